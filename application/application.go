@@ -3,10 +3,12 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"sync/atomic"
 
+	"github.com/deadelus/go-clean-app/v2/identity"
 	"github.com/deadelus/go-clean-app/v2/lifecycle"
 	"github.com/deadelus/go-clean-app/v2/logger"
 )
@@ -20,6 +22,8 @@ const (
 	LoggerModeEnvName = "APP_ENV"
 	// AppDebugEnvName is the environment variable name for the debug mode.
 	AppDebugEnvName = "APP_DEBUG"
+	// AppModeEnvName is the environment variable name for the run mode.
+	AppModeEnvName = "APP_MODE"
 )
 
 // Application interface defines the methods for the application context.
@@ -33,6 +37,7 @@ type Application interface {
 	Logger() logger.Logger
 	CurrentUser() string
 	UserAgent() string
+	RunMode() RunMode
 }
 
 // Engine is the main application structure that implements the Application interface.
@@ -42,9 +47,23 @@ type Application interface {
 type Engine struct {
 	appName, appVersion, appEnv string
 	appDebug                    bool
+	mode                        RunMode
+	currentUser, userAgent      string
+	config                      any
+	configSources               map[string]string
 	ctx                         context.Context
+	cancel                      context.CancelFunc
 	gracefull                   lifecycle.Lifecycle
 	logger                      logger.Logger
+	components                  map[string]*lifecycle.Managed
+	signals                     []os.Signal
+	signalHandlers              map[os.Signal][]func(os.Signal)
+	forceExit                   bool
+	received                    atomic.Pointer[os.Signal]
+	failure                     atomic.Pointer[error]
+	exitCodes                   ExitCodes
+	exitCodeMappings            []exitCodeMapping
+	errs                        []error
 }
 
 // Force interface compliance
@@ -54,31 +73,44 @@ var _ Application = &Engine{}
 // New creates a new Engine instance with the specified application name and version.
 // It initializes the context, logger, and graceful shutdown manager.
 // It also sets up signal handling for graceful shutdown.
+// Every option is applied, even after a failure, and the errors are aggregated and returned.
+// On failure, the shutdown hooks registered by the applied options are run before New returns.
 func New(options ...Option) (*Engine, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	engine, err := newEngine(options)
+	if err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
 
-	go func() {
-		select {
-		case <-c:
-			signal.Stop(c)
-			cancel()
-		case <-ctx.Done():
-			// Context cancelled, do nothing
-		}
-	}()
+// newEngine is New, but returns the Engine even on failure, once shut down,
+// so that the options applied, such as the exit codes, remain available to Main.
+func newEngine(options []Option) (*Engine, error) {
+	ctx, cancel := context.WithCancel(context.Background())
 
 	engine := &Engine{
-		ctx:       ctx,
-		gracefull: lifecycle.NewGracefullShutdown(ctx),
+		ctx:        ctx,
+		cancel:     cancel,
+		gracefull:  lifecycle.NewGracefullShutdown(ctx),
+		components: make(map[string]*lifecycle.Managed),
+		signals:    defaultSignals,
+		exitCodes:  DefaultExitCodes,
 	}
 
 	for _, option := range options {
 		option(engine)
 	}
 
+	if err := errors.Join(engine.errs...); err != nil {
+		cancel()
+		<-engine.gracefull.Done()
+		return engine, err
+	}
+
+	if engine.mode != ModeTest {
+		engine.handleSignals(ctx)
+	}
+
 	if engine.appName == "" {
 		engine.appName = "application"
 	}
@@ -91,9 +123,125 @@ func New(options ...Option) (*Engine, error) {
 		engine.appEnv = "development"
 	}
 
+	if engine.currentUser == "" {
+		engine.currentUser = resolveCurrentUser()
+	}
+
+	if engine.userAgent == "" {
+		engine.userAgent = buildUserAgent(engine.appName, engine.appVersion)
+	}
+
+	// The work done with the application context runs on behalf of the process
+	engine.ctx = identity.WithPrincipal(engine.ctx, engine.processPrincipal())
+
 	return engine, nil
 }
 
+// Start validates the configuration loaded with WithConfig or WithConfigStore, logs it at debug level,
+// and executes the startup hooks registered with Gracefull().OnStart, in order.
+// If the configuration is invalid or a hook fails, the application is shut down: the stop hooks
+// of the components already started are run, and Start returns once the shutdown is complete.
+// Starting an application already started returns lifecycle.ErrAlreadyStarted and leaves it running,
+// while starting it once the shutdown has begun returns lifecycle.ErrShuttingDown once the shutdown is complete.
+func (e *Engine) Start(ctx context.Context) error {
+	err := e.checkConfig()
+	if err == nil {
+		err = e.gracefull.Start(ctx)
+	}
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, lifecycle.ErrAlreadyStarted):
+		return err
+	case !errors.Is(err, lifecycle.ErrShuttingDown):
+		e.cancel()
+	}
+
+	_, shutdownErr := e.gracefull.Wait()
+	return errors.Join(fmt.Errorf("failed to start application: %w", err), shutdownErr)
+}
+
+// Run starts the application and blocks until its shutdown is complete.
+// It returns the startup error, or the errors of the shutdown hooks.
+func (e *Engine) Run() error {
+	if err := e.Start(e.ctx); err != nil {
+		return err
+	}
+
+	_, err := e.gracefull.Wait()
+	return err
+}
+
+// Add registers long-running components: each one is started by Start, in its own goroutine,
+// and stopped during the graceful shutdown. A component implementing DependsOn() []string
+// is started after, and stopped before, the components it depends on.
+// A component implementing SetLogger(logger.Logger) receives a logger named after it.
+// If a running component exits with an error, the whole application is shut down.
+// Adding a component fails if its name is already used by a startup or shutdown hook.
+func (e *Engine) Add(components ...lifecycle.Component) error {
+	for _, c := range components {
+		name := c.Name()
+		if _, exists := e.components[name]; exists {
+			return fmt.Errorf("failed to add component %s: already added", name)
+		}
+
+		var opts []lifecycle.HookOption
+		if d, ok := c.(interface{ DependsOn() []string }); ok {
+			opts = append(opts, lifecycle.DependsOn(d.DependsOn()...))
+		}
+
+		if l, ok := c.(interface{ SetLogger(logger.Logger) }); ok && e.logger != nil {
+			l.SetLogger(e.logger.Named(name))
+		}
+
+		m := lifecycle.Manage(c, func(err error) {
+			e.failure.CompareAndSwap(nil, &err)
+			if e.logger != nil {
+				e.logger.Error("Component exited unexpectedly, shutting down", map[string]any{"component": name, "error": err})
+			}
+			e.cancel()
+		})
+
+		// The stop hook is registered first, so that a name already used by a shutdown hook
+		// is rejected before the component can be started
+		if err := e.gracefull.RegisterContext(name, m.Stop, opts...); err != nil {
+			return fmt.Errorf("failed to add component %s: %w", name, err)
+		}
+		if err := e.gracefull.OnStart(name, m.Start, opts...); err != nil {
+			e.gracefull.Unregister(name)
+			return fmt.Errorf("failed to add component %s: %w", name, err)
+		}
+
+		e.components[name] = m
+	}
+
+	return nil
+}
+
+// ComponentState returns the state of a component added with Add.
+func (e *Engine) ComponentState(name string) (lifecycle.State, bool) {
+	m, exists := e.components[name]
+	if !exists {
+		return lifecycle.StateCreated, false
+	}
+	return m.State(), true
+}
+
+// ReceivedSignal returns the signal that triggered the graceful shutdown, or nil if there is none.
+func (e *Engine) ReceivedSignal() os.Signal {
+	if sig := e.received.Load(); sig != nil {
+		return *sig
+	}
+	return nil
+}
+
+// Shutdown cancels the application context, which triggers the graceful shutdown.
+// It does not wait for the shutdown to complete, see Gracefull().Wait.
+func (e *Engine) Shutdown() {
+	e.cancel()
+}
+
 // Name returns the name of the application.
 func (e *Engine) Name() string {
 	return e.appName
@@ -124,21 +272,16 @@ func (e *Engine) Gracefull() lifecycle.Lifecycle {
 	return e.gracefull
 }
 
-// CurrentUser returns the current user of the application.
+// CurrentUser returns the identity the application runs as: the one set with WithCurrentUser,
+// or the name of the user running the process.
 func (e *Engine) CurrentUser() string {
-	// Implement logic to retrieve the current user
-	return "default-user"
+	return e.currentUser
 }
 
-// UserAgent returns the user agent of the application.
+// UserAgent returns the product string identifying the application in outbound requests,
+// e.g. "my-service/1.2.0 (go1.24.5; linux/amd64)", unless set with WithUserAgent.
 func (e *Engine) UserAgent() string {
-	// Implement logic to retrieve the user agent
-	return "default-user-agent"
-}
-
-// CLIMode checks if the application is running in CLI mode.
-func (e *Engine) CLIMode() bool {
-	return e.ctx.Value("cli_mode") == true
+	return e.userAgent
 }
 
 // Logger returns the logger instance for the application.
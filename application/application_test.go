@@ -1,14 +1,28 @@
 package application_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/deadelus/go-clean-app/v2/application"
+	"github.com/deadelus/go-clean-app/v2/config"
+	"github.com/deadelus/go-clean-app/v2/identity"
+	"github.com/deadelus/go-clean-app/v2/lifecycle"
+	"github.com/deadelus/go-clean-app/v2/logger"
 	"github.com/deadelus/go-clean-app/v2/logger/zaplogger"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -18,10 +32,40 @@ type mockLifecycle struct {
 	err error
 }
 
-func (m *mockLifecycle) Register(name string, fn func() error) error {
+func (m *mockLifecycle) Register(name string, fn func() error, opts ...lifecycle.HookOption) error {
 	return m.err
 }
 
+func (m *mockLifecycle) RegisterContext(name string, fn func(context.Context) error, opts ...lifecycle.HookOption) error {
+	return m.err
+}
+
+func (m *mockLifecycle) Unregister(name string) bool {
+	return false
+}
+
+func (m *mockLifecycle) OnStart(name string, fn func(context.Context) error, opts ...lifecycle.HookOption) error {
+	return m.err
+}
+
+func (m *mockLifecycle) Start(ctx context.Context) error {
+	return m.err
+}
+
+func (m *mockLifecycle) Wait() (lifecycle.Report, error) {
+	return lifecycle.Report{}, nil
+}
+
+func (m *mockLifecycle) ShuttingDown() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+func (m *mockLifecycle) State() lifecycle.State {
+	return lifecycle.StateStopped
+}
+
 func (m *mockLifecycle) Done() <-chan struct{} {
 	ch := make(chan struct{})
 	close(ch)
@@ -62,6 +106,7 @@ func TestNew(t *testing.T) {
 		)
 		require.NoError(t, err)
 		assert.True(t, app.CLIMode())
+		assert.Equal(t, application.ModeCLI, app.RunMode())
 	})
 }
 
@@ -76,9 +121,10 @@ func TestEngine_Methods(t *testing.T) {
 	assert.NotNil(t, app.Context())
 	assert.NotNil(t, app.Gracefull())
 	assert.NotNil(t, app.Logger())
-	assert.Equal(t, "default-user", app.CurrentUser())
-	assert.Equal(t, "default-user-agent", app.UserAgent())
+	assert.NotEmpty(t, app.CurrentUser())
+	assert.Equal(t, fmt.Sprintf("TEST/1.0.0 (%s; %s/%s)", runtime.Version(), runtime.GOOS, runtime.GOARCH), app.UserAgent())
 	assert.False(t, app.CLIMode())
+	assert.Equal(t, application.ModeService, app.RunMode())
 }
 
 func TestSignalHandling(t *testing.T) {
@@ -113,48 +159,51 @@ func TestEngine_SetContext(t *testing.T) {
 func TestLoggerRegistrationErrors(t *testing.T) {
 	mockErr := errors.New("mock error")
 
-	t.Run("error in SetZapLogger should panic", func(t *testing.T) {
-		assert.Panics(t, func() {
-			application.New(
-				func(e *application.Engine) {
-					e.SetGracefull(&mockLifecycle{err: mockErr})
-				},
-				zaplogger.SetZapLogger(),
-			)
-		})
+	t.Run("error in SetZapLogger is returned", func(t *testing.T) {
+		app, err := application.New(
+			func(e *application.Engine) {
+				e.SetGracefull(&mockLifecycle{err: mockErr})
+			},
+			zaplogger.SetZapLogger(),
+		)
+		require.ErrorIs(t, err, mockErr)
+		assert.Contains(t, err.Error(), "failed to register zap logger for graceful shutdown")
+		assert.Nil(t, app)
 	})
 
-	t.Run("error in SetZapLoggerForCLI should panic", func(t *testing.T) {
-		assert.Panics(t, func() {
-			application.New(
-				application.WithCLIMode(),
-				func(e *application.Engine) {
-					e.SetGracefull(&mockLifecycle{err: mockErr})
-				},
-				zaplogger.SetZapLoggerForCLI(),
-			)
-		})
+	t.Run("error in SetZapLoggerForCLI is returned", func(t *testing.T) {
+		app, err := application.New(
+			application.WithCLIMode(),
+			func(e *application.Engine) {
+				e.SetGracefull(&mockLifecycle{err: mockErr})
+			},
+			zaplogger.SetZapLoggerForCLI(),
+		)
+		require.ErrorIs(t, err, mockErr)
+		assert.Contains(t, err.Error(), "failed to register zap logger for CLI graceful shutdown")
+		assert.Nil(t, app)
 	})
 }
 
 func TestLoggerCreationErrors(t *testing.T) {
 	mockErr := errors.New("mock logger creation error")
 
-	t.Run("error in NewLogger should panic", func(t *testing.T) {
+	t.Run("error in NewLogger is returned", func(t *testing.T) {
 		originalNewLogger := zaplogger.NewZapLogger
 		zaplogger.NewZapLogger = func(string, string, string, bool) (*zaplogger.ZapLogger, zaplogger.Gracefull, error) {
 			return nil, nil, mockErr
 		}
 		defer func() { zaplogger.NewZapLogger = originalNewLogger }()
 
-		assert.Panics(t, func() {
-			application.New(
-				zaplogger.SetZapLogger(),
-			)
-		})
+		app, err := application.New(
+			zaplogger.SetZapLogger(),
+		)
+		require.ErrorIs(t, err, mockErr)
+		assert.Contains(t, err.Error(), "failed to create zap logger")
+		assert.Nil(t, app)
 	})
 
-	t.Run("error in NewLoggerForCLI should panic", func(t *testing.T) {
+	t.Run("error in NewLoggerForCLI is returned", func(t *testing.T) {
 		originalNewZapLoggerForCLI := zaplogger.NewZapLoggerForCLI
 		zaplogger.NewZapLoggerForCLI = func() zap.Config {
 			// Return a config that will cause Build to fail
@@ -173,11 +222,1107 @@ func TestLoggerCreationErrors(t *testing.T) {
 		}
 		defer func() { zaplogger.NewZapLoggerForCLI = originalNewZapLoggerForCLI }()
 
-		assert.Panics(t, func() {
-			application.New(
-				application.WithCLIMode(),
-				zaplogger.SetZapLoggerForCLI(),
-			)
+		app, err := application.New(
+			application.WithCLIMode(),
+			zaplogger.SetZapLoggerForCLI(),
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create zap logger for CLI")
+		assert.Nil(t, app)
+	})
+}
+
+func TestNew_OptionErrors(t *testing.T) {
+	t.Run("errors are aggregated", func(t *testing.T) {
+		err1 := errors.New("first error")
+		err2 := errors.New("second error")
+		applied := false
+
+		app, err := application.New(
+			application.OptionE(func(e *application.Engine) error { return err1 }).Option(),
+			application.AppName("still-applied"),
+			application.Option(func(e *application.Engine) { applied = e.Name() == "still-applied" }),
+			application.OptionE(func(e *application.Engine) error { return err2 }).Option(),
+		)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, err1)
+		assert.ErrorIs(t, err, err2)
+		assert.True(t, applied)
+		assert.Nil(t, app)
+	})
+
+	t.Run("registered hooks are run on failure", func(t *testing.T) {
+		cleaned := false
+
+		_, err := application.New(
+			application.OptionE(func(e *application.Engine) error {
+				return e.Gracefull().Register("resource", func() error {
+					cleaned = true
+					return nil
+				})
+			}).Option(),
+			application.OptionE(func(e *application.Engine) error { return errors.New("failure") }).Option(),
+		)
+		require.Error(t, err)
+		assert.True(t, cleaned)
+	})
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		options     func() []application.Option
+		wantName    string
+		wantVersion string
+		wantEnv     string
+		wantDebug   bool
+		wantMode    application.RunMode
+		wantErr     string
+	}{
+		{
+			name:        "unset variables keep defaults",
+			options:     func() []application.Option { return []application.Option{application.FromEnv()} },
+			wantName:    "application",
+			wantVersion: "0.1.0",
+			wantEnv:     "development",
+		},
+		{
+			name: "all variables set",
+			env: map[string]string{
+				"APP_NAME":    "env-app",
+				"APP_VERSION": "3.1.4",
+				"APP_ENV":     "production",
+				"APP_DEBUG":   "true",
+				"APP_MODE":    "Job",
+			},
+			options:     func() []application.Option { return []application.Option{application.FromEnv()} },
+			wantName:    "env-app",
+			wantVersion: "3.1.4",
+			wantEnv:     "production",
+			wantDebug:   true,
+			wantMode:    application.ModeJob,
+		},
+		{
+			name: "empty variables are ignored",
+			env:  map[string]string{"APP_NAME": "", "APP_DEBUG": ""},
+			options: func() []application.Option {
+				return []application.Option{application.AppName("explicit"), application.Debug(true), application.FromEnv()}
+			},
+			wantName:    "explicit",
+			wantVersion: "0.1.0",
+			wantEnv:     "development",
+			wantDebug:   true,
+		},
+		{
+			name: "env overrides preceding options",
+			env:  map[string]string{"APP_NAME": "env-app", "APP_DEBUG": "false"},
+			options: func() []application.Option {
+				return []application.Option{application.AppName("explicit"), application.Debug(true), application.FromEnv()}
+			},
+			wantName:    "env-app",
+			wantVersion: "0.1.0",
+			wantEnv:     "development",
+		},
+		{
+			name: "following options override env",
+			env:  map[string]string{"APP_NAME": "env-app", "APP_ENV": "production"},
+			options: func() []application.Option {
+				return []application.Option{application.FromEnv(), application.AppName("explicit")}
+			},
+			wantName:    "explicit",
+			wantVersion: "0.1.0",
+			wantEnv:     "production",
+		},
+		{
+			name: "prefixed variables",
+			env: map[string]string{
+				"APP_NAME":         "ignored",
+				"BILLING_APP_NAME": "billing",
+				"BILLING_APP_ENV":  "staging",
+			},
+			options: func() []application.Option {
+				return []application.Option{application.FromEnvWithPrefix("BILLING_")}
+			},
+			wantName:    "billing",
+			wantVersion: "0.1.0",
+			wantEnv:     "staging",
+		},
+		{
+			name:    "malformed debug value",
+			env:     map[string]string{"APP_DEBUG": "maybe"},
+			options: func() []application.Option { return []application.Option{application.FromEnv()} },
+			wantErr: `invalid value "maybe" for APP_DEBUG`,
+		},
+		{
+			name: "malformed prefixed debug value",
+			env:  map[string]string{"SVC_APP_DEBUG": "yes"},
+			options: func() []application.Option {
+				return []application.Option{application.FromEnvWithPrefix("SVC_")}
+			},
+			wantErr: `invalid value "yes" for SVC_APP_DEBUG`,
+		},
+		{
+			name:    "malformed mode value",
+			env:     map[string]string{"APP_MODE": "daemon"},
+			options: func() []application.Option { return []application.Option{application.FromEnv()} },
+			wantErr: `invalid value "daemon" for APP_MODE`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"APP_NAME", "APP_VERSION", "APP_ENV", "APP_DEBUG", "APP_MODE"} {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			app, err := application.New(tt.options()...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, app)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, app.Name())
+			assert.Equal(t, tt.wantVersion, app.Version())
+			assert.Equal(t, tt.wantEnv, app.Env())
+			assert.Equal(t, tt.wantDebug, app.Debug())
+			assert.Equal(t, tt.wantMode, app.RunMode())
+		})
+	}
+}
+
+func writeDotEnv(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestWithDotEnv(t *testing.T) {
+	t.Run("explicit files are loaded in order", func(t *testing.T) {
+		dir := t.TempDir()
+		first := writeDotEnv(t, dir, "first.env", "DOTENV_A=first\nDOTENV_B=first\n")
+		second := writeDotEnv(t, dir, "second.env", "DOTENV_B=second\n")
+		t.Setenv("DOTENV_A", "")
+		t.Setenv("DOTENV_B", "")
+		os.Unsetenv("DOTENV_A")
+		os.Unsetenv("DOTENV_B")
+
+		_, err := application.New(application.WithDotEnv(first, second))
+		require.NoError(t, err)
+		assert.Equal(t, "first", os.Getenv("DOTENV_A"))
+		assert.Equal(t, "second", os.Getenv("DOTENV_B"))
+	})
+
+	t.Run("already set variables are preserved", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeDotEnv(t, dir, "app.env", "DOTENV_A=file\n")
+		t.Setenv("DOTENV_A", "process")
+
+		_, err := application.New(application.WithDotEnv(path))
+		require.NoError(t, err)
+		assert.Equal(t, "process", os.Getenv("DOTENV_A"))
+	})
+
+	t.Run("override replaces already set variables", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeDotEnv(t, dir, "app.env", "DOTENV_A=file\n")
+		t.Setenv("DOTENV_A", "process")
+
+		_, err := application.New(application.WithDotEnvOverride(path))
+		require.NoError(t, err)
+		assert.Equal(t, "file", os.Getenv("DOTENV_A"))
+	})
+
+	t.Run("default files follow the environment", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDotEnv(t, dir, ".env", "APP_ENV=staging\nAPP_NAME=base\nDOTENV_A=base\n")
+		writeDotEnv(t, dir, ".env.staging", "APP_NAME=staging\n")
+		writeDotEnv(t, dir, ".env.production", "APP_NAME=production\n")
+		writeDotEnv(t, dir, ".env.local", "DOTENV_A=local\n")
+		t.Chdir(dir)
+		for _, key := range []string{"APP_NAME", "APP_ENV", "DOTENV_A"} {
+			t.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+
+		app, err := application.New(application.WithDotEnv(), application.FromEnv())
+		require.NoError(t, err)
+		assert.Equal(t, "staging", app.Env())
+		assert.Equal(t, "staging", app.Name())
+		assert.Equal(t, "local", os.Getenv("DOTENV_A"))
+	})
+
+	t.Run("missing default files are skipped", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		_, err := application.New(application.WithDotEnv())
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing explicit file is an error", func(t *testing.T) {
+		_, err := application.New(application.WithDotEnv(filepath.Join(t.TempDir(), "missing.env")))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load dotenv file")
+	})
+
+	t.Run("parse error is returned", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeDotEnv(t, dir, "broken.env", "DOTENV_A='unterminated\n")
+
+		assert.NotPanics(t, func() {
+			_, err := application.New(application.WithDotEnv(path))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "broken.env")
 		})
 	})
 }
+
+func TestShutdownTimeout(t *testing.T) {
+	t.Run("hung hook is abandoned", func(t *testing.T) {
+		app, err := application.New(application.ShutdownTimeout(20 * time.Millisecond))
+		require.NoError(t, err)
+
+		hung := make(chan struct{})
+		defer close(hung)
+		app.Gracefull().Register("hung", func() error {
+			<-hung
+			return nil
+		})
+
+		go syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+
+		select {
+		case <-app.Gracefull().Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for shutdown to complete")
+		}
+	})
+
+	t.Run("unsupported lifecycle", func(t *testing.T) {
+		_, err := application.New(
+			application.Option(func(e *application.Engine) {
+				e.SetGracefull(&mockLifecycle{})
+			}),
+			application.ShutdownTimeout(time.Second),
+		)
+		assert.ErrorContains(t, err, "does not support shutdown timeouts")
+	})
+}
+
+func TestEngine_Run(t *testing.T) {
+	t.Run("runs until shutdown", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		var calls []string
+		app.Gracefull().OnStart("database", func(ctx context.Context) error {
+			calls = append(calls, "start database")
+			return nil
+		})
+		app.Gracefull().OnStart("http", func(ctx context.Context) error {
+			calls = append(calls, "start http")
+			return nil
+		}, lifecycle.DependsOn("database"))
+		app.Gracefull().Register("database", func() error {
+			calls = append(calls, "stop database")
+			return nil
+		})
+		app.Gracefull().Register("http", func() error {
+			calls = append(calls, "stop http")
+			return nil
+		}, lifecycle.DependsOn("database"))
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			app.Shutdown()
+		}()
+
+		require.NoError(t, app.Run())
+		assert.Equal(t, []string{"start database", "start http", "stop http", "stop database"}, calls)
+	})
+
+	t.Run("startup failure rolls back started components", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		startErr := errors.New("cannot listen")
+		var calls []string
+		app.Gracefull().OnStart("database", func(ctx context.Context) error {
+			calls = append(calls, "start database")
+			return nil
+		})
+		app.Gracefull().OnStart("http", func(ctx context.Context) error {
+			return startErr
+		}, lifecycle.DependsOn("database"))
+		app.Gracefull().OnStart("worker", func(ctx context.Context) error {
+			calls = append(calls, "start worker")
+			return nil
+		}, lifecycle.DependsOn("http"))
+		app.Gracefull().Register("database", func() error {
+			calls = append(calls, "stop database")
+			return nil
+		})
+		app.Gracefull().Register("http", func() error {
+			calls = append(calls, "stop http")
+			return nil
+		})
+		app.Gracefull().Register("worker", func() error {
+			calls = append(calls, "stop worker")
+			return nil
+		})
+		app.Gracefull().Register("flush", func() error {
+			calls = append(calls, "flush")
+			return nil
+		}, lifecycle.Priority(lifecycle.PriorityLast))
+
+		err = app.Run()
+		require.ErrorIs(t, err, startErr)
+		assert.Contains(t, err.Error(), "failed to start application: http: cannot listen")
+		assert.Equal(t, []string{"start database", "stop database", "flush"}, calls)
+		assert.Error(t, app.Context().Err())
+	})
+
+	t.Run("starting twice leaves the application running", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		assert.ErrorIs(t, app.Start(context.Background()), lifecycle.ErrAlreadyStarted)
+		assert.ErrorIs(t, app.Run(), lifecycle.ErrAlreadyStarted)
+		assert.NoError(t, app.Context().Err(), "the application is not shut down")
+		assert.Equal(t, lifecycle.StateRunning, app.Gracefull().State())
+
+		app.Shutdown()
+		_, err = app.Gracefull().Wait()
+		assert.NoError(t, err)
+	})
+
+	t.Run("starting once shut down", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		app.Shutdown()
+		app.Gracefull().Wait()
+		assert.ErrorIs(t, app.Start(context.Background()), lifecycle.ErrShuttingDown)
+	})
+}
+
+// testComponent runs until it is stopped or fails with the error sent on fail.
+type testComponent struct {
+	name      string
+	dependsOn []string
+	stop      chan struct{}
+	fail      chan error
+	logger    logger.Logger
+}
+
+func newTestComponent(name string, dependsOn ...string) *testComponent {
+	return &testComponent{name: name, dependsOn: dependsOn, stop: make(chan struct{}), fail: make(chan error, 1)}
+}
+
+func (c *testComponent) Name() string              { return c.name }
+func (c *testComponent) DependsOn() []string       { return c.dependsOn }
+func (c *testComponent) SetLogger(l logger.Logger) { c.logger = l }
+
+func (c *testComponent) Start(ctx context.Context) error {
+	select {
+	case <-c.stop:
+		return nil
+	case err := <-c.fail:
+		return err
+	}
+}
+
+func (c *testComponent) Stop(ctx context.Context) error {
+	close(c.stop)
+	return nil
+}
+
+func TestEngine_Add(t *testing.T) {
+	t.Run("components are started and stopped", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		database := newTestComponent("database")
+		server := newTestComponent("http", "database")
+		require.NoError(t, app.Add(server, database))
+
+		state, ok := app.ComponentState("http")
+		assert.True(t, ok)
+		assert.Equal(t, lifecycle.StateCreated, state)
+		_, ok = app.ComponentState("missing")
+		assert.False(t, ok)
+
+		require.NoError(t, app.Start(context.Background()))
+		state, _ = app.ComponentState("http")
+		assert.Equal(t, lifecycle.StateRunning, state)
+
+		app.Shutdown()
+		_, err = app.Gracefull().Wait()
+		require.NoError(t, err)
+
+		for _, name := range []string{"database", "http"} {
+			state, _ = app.ComponentState(name)
+			assert.Equal(t, lifecycle.StateStopped, state)
+		}
+	})
+
+	t.Run("failing component shuts the application down", func(t *testing.T) {
+		app, err := application.New(zaplogger.SetZapLogger())
+		require.NoError(t, err)
+
+		worker := newTestComponent("worker")
+		require.NoError(t, app.Add(worker))
+		require.NoError(t, app.Start(context.Background()))
+
+		worker.fail <- errors.New("connection lost")
+
+		select {
+		case <-app.Context().Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for context to be canceled")
+		}
+		_, err = app.Gracefull().Wait()
+		assert.ErrorContains(t, err, "worker exited: connection lost")
+
+		state, _ := app.ComponentState("worker")
+		assert.Equal(t, lifecycle.StateFailed, state)
+	})
+
+	t.Run("failing component makes Run fail", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		server := newTestComponent("http")
+		server.fail <- errors.New("bind: address already in use")
+		require.NoError(t, app.Add(server))
+
+		assert.ErrorContains(t, app.Run(), "http exited: bind: address already in use")
+	})
+
+	t.Run("duplicate component", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		require.NoError(t, app.Add(newTestComponent("worker")))
+		assert.ErrorContains(t, app.Add(newTestComponent("worker")), "already added")
+	})
+
+	t.Run("component added once started", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+		require.NoError(t, app.Start(context.Background()))
+
+		assert.ErrorIs(t, app.Add(newTestComponent("late")), lifecycle.ErrAlreadyStarted)
+	})
+
+	t.Run("name used by a shutdown hook", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		closed := false
+		require.NoError(t, app.Gracefull().Register("worker", func() error {
+			closed = true
+			return nil
+		}))
+
+		worker := newTestComponent("worker")
+		assert.ErrorIs(t, app.Add(worker), lifecycle.ErrAlreadyRegistered)
+		assert.Empty(t, app.Gracefull().(*lifecycle.Gracefull).StartStages(), "the component is not started")
+
+		app.Shutdown()
+		_, err = app.Gracefull().Wait()
+		require.NoError(t, err)
+		assert.True(t, closed)
+	})
+
+	t.Run("name used by a startup hook", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+
+		require.NoError(t, app.Gracefull().OnStart("worker", func(context.Context) error { return nil }))
+
+		assert.ErrorIs(t, app.Add(newTestComponent("worker")), lifecycle.ErrAlreadyRegistered)
+		assert.False(t, app.Gracefull().Unregister("worker"), "the stop hook is rolled back")
+	})
+}
+
+func TestSignalOptions(t *testing.T) {
+	t.Run("handler for a non-terminating signal", func(t *testing.T) {
+		received := make(chan os.Signal, 1)
+		app, err := application.New(
+			application.OnSignal(syscall.SIGUSR1, func(sig os.Signal) { received <- sig }),
+		)
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+
+		select {
+		case sig := <-received:
+			assert.Equal(t, syscall.SIGUSR1, sig)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the signal handler")
+		}
+		assert.NoError(t, app.Context().Err())
+	})
+
+	t.Run("custom terminating signals", func(t *testing.T) {
+		app, err := application.New(application.Signals(syscall.SIGUSR2))
+		require.NoError(t, err)
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+		select {
+		case <-app.Context().Done():
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for context to be canceled")
+		}
+	})
+
+	t.Run("second signal forces exit", func(t *testing.T) {
+		exited := make(chan int, 1)
+		originalExit := application.Exit
+		application.Exit = func(code int) { exited <- code }
+		defer func() { application.Exit = originalExit }()
+
+		app, err := application.New(
+			application.Signals(syscall.SIGUSR2),
+			application.ForceExitOnSecondSignal(),
+		)
+		require.NoError(t, err)
+
+		// A slow hook keeps the shutdown in progress until the exit is forced
+		release := make(chan struct{})
+		app.Gracefull().Register("slow", func() error {
+			<-release
+			return nil
+		})
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+		<-app.Gracefull().ShuttingDown()
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+
+		select {
+		case code := <-exited:
+			assert.Equal(t, application.ExitCodeForced, code)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the forced exit")
+		}
+		close(release)
+		<-app.Gracefull().Done()
+	})
+
+	t.Run("goroutine dump", func(t *testing.T) {
+		var buf bytes.Buffer
+		application.DumpGoroutines(&buf)(syscall.SIGQUIT)
+
+		assert.Contains(t, buf.String(), "Received quit, dumping goroutines")
+		assert.Contains(t, buf.String(), "TestSignalOptions")
+	})
+}
+
+// exitCoderError is an error carrying its own exit code.
+type exitCoderError struct{ code int }
+
+func (e exitCoderError) Error() string { return fmt.Sprintf("exit %d", e.code) }
+func (e exitCoderError) ExitCode() int { return e.code }
+
+func TestMain_ExitCodes(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	shutdown := func(app application.Application) error {
+		app.(*application.Engine).Shutdown()
+		return nil
+	}
+
+	crashing := func(err error) *testComponent {
+		c := newTestComponent("worker")
+		c.fail <- err
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		fn       func(app application.Application) error
+		opts     []application.Option
+		env      map[string]string
+		expected int
+	}{
+		{
+			name:     "clean shutdown",
+			fn:       shutdown,
+			expected: application.ExitCodeOK,
+		},
+		{
+			name: "invalid option",
+			fn:   shutdown,
+			opts: []application.Option{
+				application.OptionE(func(e *application.Engine) error { return errors.New("boom") }).Option(),
+			},
+			expected: application.ExitCodeStartup,
+		},
+		{
+			name: "startup failure",
+			fn:   shutdown,
+			opts: []application.Option{
+				application.OptionE(func(e *application.Engine) error {
+					return e.Gracefull().OnStart("db", func(context.Context) error { return errors.New("refused") })
+				}).Option(),
+			},
+			expected: application.ExitCodeStartup,
+		},
+		{
+			name:     "runtime failure",
+			fn:       func(application.Application) error { return errors.New("boom") },
+			expected: application.ExitCodeRuntime,
+		},
+		{
+			name:     "component failure",
+			fn:       func(application.Application) error { return nil },
+			opts:     []application.Option{application.WithComponents(crashing(errors.New("connection lost")))},
+			expected: application.ExitCodeRuntime,
+		},
+		{
+			name: "mapped component error",
+			fn:   func(application.Application) error { return nil },
+			opts: []application.Option{
+				application.ExitCodeFor(errNotFound, 4),
+				application.WithComponents(crashing(errNotFound)),
+			},
+			expected: 4,
+		},
+		{
+			name: "unclean shutdown",
+			fn:   shutdown,
+			opts: []application.Option{
+				application.OptionE(func(e *application.Engine) error {
+					return e.Gracefull().Register("db", func() error { return errors.New("close failed") })
+				}).Option(),
+			},
+			expected: application.ExitCodeUnclean,
+		},
+		{
+			name: "signal",
+			fn: func(application.Application) error {
+				return syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+			},
+			opts:     []application.Option{application.Signals(syscall.SIGUSR2)},
+			expected: application.ExitCodeSignal,
+		},
+		{
+			name: "mapped error",
+			fn: func(application.Application) error {
+				return fmt.Errorf("failed to load user: %w", errNotFound)
+			},
+			opts:     []application.Option{application.ExitCodeFor(errNotFound, 4)},
+			expected: 4,
+		},
+		{
+			name:     "exit coder",
+			fn:       func(application.Application) error { return exitCoderError{code: 5} },
+			expected: 5,
+		},
+		{
+			name: "custom exit codes",
+			fn:   func(application.Application) error { return errors.New("boom") },
+			opts: []application.Option{
+				application.WithExitCodes(application.ExitCodes{Startup: 10, Runtime: 11, Unclean: 12, Signal: 0}),
+			},
+			expected: 11,
+		},
+		{
+			name: "custom exit codes on invalid option",
+			fn:   shutdown,
+			opts: []application.Option{
+				application.WithExitCodes(application.ExitCodes{Startup: 10, Runtime: 11, Unclean: 12, Signal: 0}),
+				application.OptionE(func(e *application.Engine) error { return errors.New("boom") }).Option(),
+			},
+			expected: 10,
+		},
+		{
+			name: "mapped invalid option error",
+			fn:   shutdown,
+			opts: []application.Option{
+				application.OptionE(func(e *application.Engine) error { return fmt.Errorf("boom: %w", errNotFound) }).Option(),
+				application.ExitCodeFor(errNotFound, 4),
+			},
+			expected: 4,
+		},
+		{
+			name: "invalid environment with custom exit codes",
+			fn:   shutdown,
+			env:  map[string]string{"APP_DEBUG": "maybe"},
+			opts: []application.Option{
+				application.WithExitCodes(application.ExitCodes{Startup: 10}),
+				application.FromEnv(),
+			},
+			expected: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []int
+			originalExit := application.Exit
+			application.Exit = func(code int) { codes = append(codes, code) }
+			defer func() { application.Exit = originalExit }()
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			application.Main(tt.fn, tt.opts...)
+
+			assert.Equal(t, []int{tt.expected}, codes)
+		})
+	}
+}
+
+func TestMain_ClosesLogger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLogger := logger.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Error("Application failed", gomock.Any())
+	mockLogger.EXPECT().Close()
+
+	var codes []int
+	originalExit := application.Exit
+	application.Exit = func(code int) { codes = append(codes, code) }
+	defer func() { application.Exit = originalExit }()
+
+	application.Main(
+		func(application.Application) error { return errors.New("boom") },
+		application.Option(func(e *application.Engine) { e.SetLogger(mockLogger) }),
+	)
+
+	assert.Equal(t, []int{application.ExitCodeRuntime}, codes)
+}
+
+func TestRunMode(t *testing.T) {
+	t.Run("names", func(t *testing.T) {
+		for _, mode := range []application.RunMode{application.ModeService, application.ModeCLI, application.ModeJob, application.ModeTest} {
+			parsed, err := application.ParseRunMode(strings.ToUpper(mode.String()))
+			require.NoError(t, err)
+			assert.Equal(t, mode, parsed)
+		}
+
+		_, err := application.ParseRunMode("daemon")
+		assert.Error(t, err)
+		assert.Equal(t, "RunMode(42)", application.RunMode(42).String())
+	})
+
+	t.Run("mode survives SetContext", func(t *testing.T) {
+		app, err := application.New(application.WithCLIMode())
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		app.SetContext(context.Background())
+		assert.True(t, app.CLIMode())
+	})
+
+	t.Run("job and cli modes shut down when the main function returns", func(t *testing.T) {
+		for _, mode := range []application.RunMode{application.ModeCLI, application.ModeJob} {
+			var codes []int
+			originalExit := application.Exit
+			application.Exit = func(code int) { codes = append(codes, code) }
+
+			application.Main(func(application.Application) error { return nil }, application.WithRunMode(mode))
+
+			application.Exit = originalExit
+			assert.Equal(t, []int{application.ExitCodeOK}, codes, mode.String())
+		}
+	})
+
+	t.Run("logger adapts to the mode", func(t *testing.T) {
+		app, err := application.New(application.WithCLIMode(), zaplogger.SetZapLogger())
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.Equal(t, [][]string{{"zaplogger-cli"}}, app.Gracefull().(*lifecycle.Gracefull).Stages())
+
+		app, err = application.New(application.WithRunMode(application.ModeTest), zaplogger.SetZapLogger())
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.Empty(t, app.Gracefull().(*lifecycle.Gracefull).Stages())
+		assert.False(t, app.Logger().(*zaplogger.ZapLogger).Logger.Core().Enabled(zap.ErrorLevel))
+	})
+
+	t.Run("mode set after the logger", func(t *testing.T) {
+		t.Setenv("APP_MODE", "cli")
+
+		app, err := application.New(zaplogger.SetZapLogger(), application.FromEnv())
+		assert.Nil(t, app)
+		assert.ErrorContains(t, err, "APP_MODE: cannot set the run mode to cli: a logger is already set for the service mode")
+
+		_, err = application.New(zaplogger.SetZapLogger(), application.WithRunMode(application.ModeJob))
+		assert.ErrorContains(t, err, "cannot set the run mode to job")
+
+		app, err = application.New(zaplogger.SetZapLogger(), application.WithRunMode(application.ModeService))
+		require.NoError(t, err, "the mode of the logger is kept")
+		app.Shutdown()
+	})
+
+	t.Run("test mode ignores signals", func(t *testing.T) {
+		app, err := application.New(application.WithRunMode(application.ModeTest), application.Signals(syscall.SIGUSR2))
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		// Without handler, SIGUSR2 would kill the process: listen to it outside of the engine
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, syscall.SIGUSR2)
+		defer signal.Stop(received)
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+		<-received
+
+		assert.NoError(t, app.Context().Err())
+	})
+}
+
+func TestEngine_Identity(t *testing.T) {
+	t.Run("current user from the system", func(t *testing.T) {
+		originalLookup := application.LookupCurrentUser
+		application.LookupCurrentUser = func() (*user.User, error) { return &user.User{Username: "alice"}, nil }
+		defer func() { application.LookupCurrentUser = originalLookup }()
+
+		app, err := application.New()
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.Equal(t, "alice", app.CurrentUser())
+	})
+
+	t.Run("current user without passwd entry", func(t *testing.T) {
+		originalLookup := application.LookupCurrentUser
+		application.LookupCurrentUser = func() (*user.User, error) { return nil, user.UnknownUserIdError(1000) }
+		defer func() { application.LookupCurrentUser = originalLookup }()
+
+		t.Setenv("USER", "")
+		t.Setenv("LOGNAME", "bob")
+		app, err := application.New()
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.Equal(t, "bob", app.CurrentUser())
+
+		t.Setenv("LOGNAME", "")
+		app, err = application.New()
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.Equal(t, fmt.Sprint(os.Getuid()), app.CurrentUser())
+	})
+
+	t.Run("overridden by options", func(t *testing.T) {
+		app, err := application.New(
+			application.WithCurrentUser("billing-service"),
+			application.WithUserAgent("billing/2.0"),
+		)
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.Equal(t, "billing-service", app.CurrentUser())
+		assert.Equal(t, "billing/2.0", app.UserAgent())
+	})
+
+	t.Run("user agent is a valid product", func(t *testing.T) {
+		app, err := application.New(application.AppName("my service (beta)"), application.Version("1.0/rc1"))
+		require.NoError(t, err)
+		defer app.Shutdown()
+		assert.True(t, strings.HasPrefix(app.UserAgent(), "my-service--beta-/1.0-rc1 (go"), app.UserAgent())
+	})
+}
+
+func TestEngine_Principal(t *testing.T) {
+	app, err := application.New(application.WithCurrentUser("billing-service"))
+	require.NoError(t, err)
+	defer app.Shutdown()
+
+	process := identity.Principal{Subject: "billing-service", AuthMethod: identity.AuthMethodProcess}
+
+	p, ok := identity.PrincipalFrom(app.Context())
+	assert.True(t, ok)
+	assert.Equal(t, process, p)
+	assert.Equal(t, process, app.Principal(context.Background()))
+
+	alice := identity.Principal{Subject: "alice", Tenant: "acme", AuthMethod: "jwt"}
+	assert.Equal(t, alice, app.Principal(identity.WithPrincipal(app.Context(), alice)))
+}
+
+func TestWithConfig(t *testing.T) {
+	type appConfig struct {
+		URL  string `env:"TEST_DB_URL" required:"true"`
+		Port int    `env:"TEST_PORT" default:"8080"`
+	}
+
+	t.Run("loaded", func(t *testing.T) {
+		t.Setenv("TEST_DB_URL", "postgres://localhost")
+
+		var cfg appConfig
+		app, err := application.New(application.WithConfig(&cfg))
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		assert.Equal(t, appConfig{URL: "postgres://localhost", Port: 8080}, cfg)
+		assert.Same(t, &cfg, app.Config())
+		assert.Equal(t, map[string]string{"URL": "env:TEST_DB_URL", "Port": "default"}, app.ConfigSources())
+	})
+
+	t.Run("environment overlay", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeDotEnv(t, ".", "config.yaml", "test_db_url: postgres://base\ntest_port: 9000\n")
+		writeDotEnv(t, ".", "config.staging.yaml", "test_db_url: postgres://staging\n")
+		t.Setenv("TEST_DB_URL", "")
+
+		var cfg appConfig
+		app, err := application.New(application.Env("staging"), application.WithConfig(&cfg))
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		assert.Equal(t, appConfig{URL: "postgres://staging", Port: 9000}, cfg)
+		assert.Equal(t, "file:config.staging.yaml", app.ConfigSources()["URL"])
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		t.Setenv("TEST_DB_URL", "")
+
+		var cfg appConfig
+		app, err := application.New(application.WithConfig(&cfg))
+		require.ErrorIs(t, err, config.ErrMissing)
+		assert.Contains(t, err.Error(), "failed to load configuration")
+		assert.Nil(t, app)
+	})
+}
+
+func TestWithConfigStore(t *testing.T) {
+	type limits struct {
+		Rate int `env:"TEST_RATE" default:"10"`
+	}
+	t.Setenv("TEST_RATE", "")
+
+	ctrl := gomock.NewController(t)
+	mockLogger := logger.NewMockLogger(ctrl)
+
+	store := config.NewStore[limits]()
+	app, err := application.New(
+		application.Option(func(e *application.Engine) { e.SetLogger(mockLogger) }),
+		application.WithConfigStore(store),
+	)
+	require.NoError(t, err)
+	defer app.Shutdown()
+
+	assert.Equal(t, 10, store.Get().Rate)
+	assert.Same(t, store, app.Config())
+	assert.Equal(t, map[string]string{"Rate": "default"}, app.ConfigSources())
+
+	changed := make(chan int, 1)
+	store.OnChange(func(_, new limits) { changed <- new.Rate })
+
+	t.Run("reload on SIGHUP", func(t *testing.T) {
+		t.Setenv("TEST_RATE", "20")
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+		select {
+		case rate := <-changed:
+			assert.Equal(t, 20, rate)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the reload")
+		}
+	})
+
+	t.Run("reload failure is logged", func(t *testing.T) {
+		logged := make(chan struct{})
+		mockLogger.EXPECT().
+			Error("Configuration reload failed, keeping the current configuration", gomock.Any()).
+			Do(func(string, ...any) { close(logged) })
+
+		t.Setenv("TEST_RATE", "fast")
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+		select {
+		case <-logged:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the reload error")
+		}
+		assert.Equal(t, 20, store.Get().Rate)
+	})
+}
+
+func TestEngine_ConfigValidation(t *testing.T) {
+	type appConfig struct {
+		Port     int           `env:"TEST_PORT" default:"8080" validate:"max=65535"`
+		Password config.Secret `env:"TEST_PASSWORD" default:"hunter2"`
+	}
+
+	t.Run("valid configuration is logged", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "")
+
+		ctrl := gomock.NewController(t)
+		mockLogger := logger.NewMockLogger(ctrl)
+		mockLogger.EXPECT().Debug("Effective configuration:\n" +
+			"Port     = 8080 (default)\n" +
+			"Password = [REDACTED] (default)\n")
+
+		var cfg appConfig
+		app, err := application.New(
+			application.Option(func(e *application.Engine) { e.SetLogger(mockLogger) }),
+			application.WithConfig(&cfg),
+		)
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		require.NoError(t, app.Start(context.Background()))
+
+		out, err := json.Marshal(app.ConfigReport())
+		require.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"field": "Port", "value": "8080", "source": "default"},
+			{"field": "Password", "value": "[REDACTED]", "source": "default"}
+		]`, string(out))
+	})
+
+	t.Run("invalid configuration aborts the startup", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "70000")
+
+		var cfg appConfig
+		app, err := application.New(application.WithConfig(&cfg))
+		require.NoError(t, err)
+
+		started := false
+		app.Gracefull().OnStart("server", func(context.Context) error {
+			started = true
+			return nil
+		})
+
+		err = app.Start(context.Background())
+		require.ErrorIs(t, err, config.ErrInvalid)
+		assert.Contains(t, err.Error(), "Port (TEST_PORT): invalid configuration: must be at most 65535")
+		assert.False(t, started)
+		assert.Equal(t, lifecycle.StateStopped, app.Gracefull().State())
+	})
+
+	t.Run("no configuration", func(t *testing.T) {
+		app, err := application.New()
+		require.NoError(t, err)
+		defer app.Shutdown()
+
+		assert.Nil(t, app.ConfigReport())
+		assert.NoError(t, app.Start(context.Background()))
+	})
+}
+
+func TestEngine_Add_Logger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLogger := logger.NewMockLogger(ctrl)
+	named := logger.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Named("database").Return(named)
+
+	app, err := application.New(application.Option(func(e *application.Engine) { e.SetLogger(mockLogger) }))
+	require.NoError(t, err)
+	defer app.Shutdown()
+
+	database := newTestComponent("database")
+	require.NoError(t, app.Add(database))
+	assert.Same(t, named, database.logger)
+}
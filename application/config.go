@@ -0,0 +1,130 @@
+package application
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/deadelus/go-clean-app/v2/config"
+)
+
+// WithConfig is an Option that populates cfg, a pointer to a configuration struct,
+// according to its tags, see the config package. The values are read, in increasing precedence,
+// from the defaults, the files "config" and "config.<env>" of the current directory (.yaml, .yml or .json),
+// the file given with the --config flag, and the environment.
+// The environment is taken from the Engine, APP_ENV or "development", in that order.
+//
+// Pass it after WithDotEnv so that the variables of the dotenv files are visible.
+// Every missing or invalid field is reported in the error returned by New.
+func WithConfig(cfg any, opts ...config.Option) Option {
+	return OptionE(func(e *Engine) error {
+		loader := config.New(append(e.configDefaults(), opts...)...)
+		if err := loader.Load(cfg); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		e.config = cfg
+		e.configSources = loader.Sources()
+		return nil
+	}).Option()
+}
+
+// WithConfigStore is an Option that loads store like WithConfig, and then reloads it on SIGHUP
+// and whenever one of its files changes, checked every config.PollInterval until the shutdown.
+// A reload failure keeps the current configuration and is logged through the Logger.
+//
+//	store := config.NewStore[Config]()
+//	app, err := application.New(zaplogger.SetZapLogger(), application.WithConfigStore(store))
+//	store.OnChange(func(old, new Config) { limiter.SetLimit(new.RateLimit) })
+func WithConfigStore[T any](store *config.Store[T], opts ...config.Option) Option {
+	return OptionE(func(e *Engine) error {
+		if err := store.Load(append(e.configDefaults(), opts...)...); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		e.config = store
+		store.OnError(func(err error) {
+			e.logError("Configuration reload failed, keeping the current configuration", err)
+		})
+
+		OnSignal(syscall.SIGHUP, func(os.Signal) {
+			if err := store.Reload(); err != nil {
+				e.logError("Configuration reload failed, keeping the current configuration", err)
+			}
+		})(e)
+
+		go store.Watch(e.ctx, config.PollInterval)
+		return nil
+	}).Option()
+}
+
+// Config returns the configuration struct loaded with WithConfig,
+// the *config.Store loaded with WithConfigStore, or nil.
+func (e *Engine) Config() any {
+	return e.config
+}
+
+// ConfigSources returns the source of each field of the configuration loaded with WithConfig
+// or WithConfigStore, by field path, e.g. "Database.URL": "env:DB_URL", see config.Loader.Sources.
+func (e *Engine) ConfigSources() map[string]string {
+	if s, ok := e.config.(interface{ Sources() map[string]string }); ok {
+		return s.Sources()
+	}
+	return e.configSources
+}
+
+// ConfigReport returns the effective configuration loaded with WithConfig or WithConfigStore,
+// with the secrets redacted, e.g. to attach its JSON encoding to a support ticket.
+func (e *Engine) ConfigReport() config.Report {
+	cfg := e.currentConfig()
+	if cfg == nil {
+		return nil
+	}
+	return config.NewReport(cfg, e.ConfigSources())
+}
+
+// currentConfig returns the configuration struct, or the current snapshot of the store.
+func (e *Engine) currentConfig() any {
+	if s, ok := e.config.(interface{ Snapshot() any }); ok {
+		return s.Snapshot()
+	}
+	return e.config
+}
+
+// checkConfig validates the configuration, see config.Validate, and logs its report at debug level.
+func (e *Engine) checkConfig() error {
+	cfg := e.currentConfig()
+	if cfg == nil {
+		return nil
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		return err
+	}
+
+	if e.logger != nil {
+		e.logger.Debug("Effective configuration:\n" + e.ConfigReport().String())
+	}
+	return nil
+}
+
+// configDefaults returns the loader options common to WithConfig and WithConfigStore:
+// the overlay of the environment and the file given with the --config flag.
+func (e *Engine) configDefaults() []config.Option {
+	opts := []config.Option{config.WithEnv(e.currentEnv())}
+	if path := config.PathFromArgs(os.Args[1:]); path != "" {
+		opts = append(opts, config.WithFiles(path))
+	}
+	return opts
+}
+
+// currentEnv returns the environment of the Engine, which may not be set yet while the options are applied.
+func (e *Engine) currentEnv() string {
+	if e.appEnv != "" {
+		return e.appEnv
+	}
+	if env, ok := lookupEnv(LoggerModeEnvName); ok {
+		return env
+	}
+	return "development"
+}
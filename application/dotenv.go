@@ -0,0 +1,102 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// DotEnvFiles returns the dotenv files loaded by WithDotEnv when no path is given,
+// in loading order: ".env", ".env.<env>" and ".env.local".
+func DotEnvFiles(env string) []string {
+	return []string{".env", ".env." + env, ".env.local"}
+}
+
+// WithDotEnv is an Option that loads variables from dotenv files into the process environment.
+// Files are read in the given order and a file overrides the values of the files before it.
+// Variables already set in the environment are preserved.
+//
+// Without paths, the files returned by DotEnvFiles are loaded and missing ones are skipped;
+// the environment is taken from the Engine, APP_ENV or the ".env" file, in that order.
+// Explicit paths must exist. Read and parse errors are returned by New.
+//
+// WithDotEnv must be passed before the options reading the environment, such as FromEnv.
+func WithDotEnv(paths ...string) Option {
+	return OptionE(func(e *Engine) error {
+		return loadDotEnv(e, paths, false)
+	}).Option()
+}
+
+// WithDotEnvOverride is like WithDotEnv but the values read from the files
+// override the variables already set in the environment.
+func WithDotEnvOverride(paths ...string) Option {
+	return OptionE(func(e *Engine) error {
+		return loadDotEnv(e, paths, true)
+	}).Option()
+}
+
+// loadDotEnv merges the dotenv files and applies the result to the process environment.
+func loadDotEnv(e *Engine, paths []string, override bool) error {
+	optional := len(paths) == 0
+
+	if optional {
+		base, err := readDotEnv(".env", true)
+		if err != nil {
+			return err
+		}
+
+		env := e.appEnv
+		if env == "" {
+			env = os.Getenv(LoggerModeEnvName)
+		}
+		if env == "" {
+			env = base[LoggerModeEnvName]
+		}
+		if env == "" {
+			env = "development"
+		}
+
+		paths = DotEnvFiles(env)
+	}
+
+	values := make(map[string]string)
+	for _, path := range paths {
+		content, err := readDotEnv(path, optional)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range content {
+			values[key] = value
+		}
+	}
+
+	for key, value := range values {
+		if _, exists := os.LookupEnv(key); exists && !override {
+			continue
+		}
+
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s from dotenv: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// readDotEnv parses a single dotenv file.
+// A missing file is reported as empty when optional is true.
+func readDotEnv(path string, optional bool) (map[string]string, error) {
+	content, err := godotenv.Read(path)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load dotenv file %s: %w", path, err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// FromEnv is an Option that reads the application name, version, environment, debug mode
+// and run mode from the APP_NAME, APP_VERSION, APP_ENV, APP_DEBUG and APP_MODE variables.
+//
+// Options are applied in order: values read by FromEnv override the options passed
+// before it and are overridden by the options passed after it. Unset or empty
+// variables leave the current value untouched.
+// A malformed APP_DEBUG or APP_MODE value (e.g. "maybe") makes New return an error.
+// As the logger options depend on the run mode, FromEnv must be passed before them.
+func FromEnv() Option {
+	return FromEnvWithPrefix("")
+}
+
+// FromEnvWithPrefix is like FromEnv but prepends prefix to every variable name,
+// e.g. FromEnvWithPrefix("BILLING_") reads BILLING_APP_NAME, BILLING_APP_VERSION, etc.
+// It allows several applications to share the same environment.
+func FromEnvWithPrefix(prefix string) Option {
+	return OptionE(func(e *Engine) error {
+		if v, ok := lookupEnv(prefix + AppNameEnvName); ok {
+			e.appName = v
+		}
+
+		if v, ok := lookupEnv(prefix + AppVersionEnvName); ok {
+			e.appVersion = v
+		}
+
+		if v, ok := lookupEnv(prefix + LoggerModeEnvName); ok {
+			e.appEnv = v
+		}
+
+		if v, ok := lookupEnv(prefix + AppDebugEnvName); ok {
+			debug, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for %s: expected a boolean", v, prefix+AppDebugEnvName)
+			}
+			e.appDebug = debug
+		}
+
+		if v, ok := lookupEnv(prefix + AppModeEnvName); ok {
+			mode, err := ParseRunMode(v)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for %s: expected service, cli, job or test", v, prefix+AppModeEnvName)
+			}
+			if err := e.setRunMode(mode); err != nil {
+				return fmt.Errorf("%s: %w", prefix+AppModeEnvName, err)
+			}
+		}
+
+		return nil
+	}).Option()
+}
+
+// lookupEnv returns the value of the environment variable named by key.
+// Empty values are reported as unset.
+func lookupEnv(key string) (string, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return "", false
+	}
+	return v, true
+}
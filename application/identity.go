@@ -0,0 +1,81 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/deadelus/go-clean-app/v2/identity"
+)
+
+// LookupCurrentUser is a hook for user.Current, can be replaced in tests.
+var LookupCurrentUser = user.Current
+
+// WithCurrentUser is an Option that sets the identity returned by CurrentUser,
+// e.g. a service account name, instead of the user running the process.
+func WithCurrentUser(name string) Option {
+	return func(e *Engine) {
+		e.currentUser = name
+	}
+}
+
+// WithUserAgent is an Option that sets the product string returned by UserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(e *Engine) {
+		e.userAgent = userAgent
+	}
+}
+
+// Principal returns the principal carried by ctx, e.g. the user of an HTTP request,
+// or the principal of the process, whose subject is CurrentUser, for the work done outside of a request.
+func (e *Engine) Principal(ctx context.Context) identity.Principal {
+	if p, ok := identity.PrincipalFrom(ctx); ok {
+		return p
+	}
+	return e.processPrincipal()
+}
+
+// processPrincipal returns the principal of the process.
+func (e *Engine) processPrincipal() identity.Principal {
+	return identity.Principal{Subject: e.currentUser, AuthMethod: identity.AuthMethodProcess}
+}
+
+// resolveCurrentUser returns the name of the user running the process.
+// Containers often run with a uid missing from /etc/passwd: the USER and LOGNAME variables
+// are then used, and finally the uid itself.
+func resolveCurrentUser() string {
+	if u, err := LookupCurrentUser(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	for _, key := range []string{"USER", "LOGNAME"} {
+		if v, ok := lookupEnv(key); ok {
+			return v
+		}
+	}
+
+	if uid := os.Getuid(); uid >= 0 {
+		return strconv.Itoa(uid)
+	}
+	return "unknown"
+}
+
+// buildUserAgent returns a product string as defined by RFC 7231, section 5.5.3,
+// e.g. "my-service/1.2.0 (go1.24.5; linux/amd64)".
+func buildUserAgent(name, version string) string {
+	return fmt.Sprintf("%s/%s (%s; %s/%s)", toToken(name), toToken(version), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// toToken replaces the characters not allowed in an HTTP token with a dash.
+func toToken(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return r
+		}
+		return '-'
+	}, s)
+}
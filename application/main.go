@@ -0,0 +1,163 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Exit codes returned by Main with DefaultExitCodes.
+const (
+	// ExitCodeOK is the exit code of an application shut down cleanly.
+	ExitCodeOK = 0
+	// ExitCodeRuntime is the exit code of an application whose main function failed.
+	ExitCodeRuntime = 1
+	// ExitCodeStartup is the exit code of an application that could not be created or started.
+	ExitCodeStartup = 2
+	// ExitCodeUnclean is the exit code of an application whose shutdown hooks failed.
+	ExitCodeUnclean = 3
+	// ExitCodeSignal is the exit code of an application terminated by a signal, as a shell reports Ctrl+C.
+	ExitCodeSignal = 130
+)
+
+// ExitCodes are the exit codes used by Main for each outcome of the application.
+type ExitCodes struct {
+	// Startup is used when the application cannot be created or a startup hook fails.
+	Startup int
+	// Runtime is used when the main function returns an error or a component exits with an error while running.
+	Runtime int
+	// Unclean is used when a shutdown hook fails.
+	Unclean int
+	// Signal is used when the shutdown is triggered by a signal and completes cleanly.
+	// Set it to ExitCodeOK for a SIGTERM to be a normal termination, e.g. in a container.
+	Signal int
+}
+
+// DefaultExitCodes are the exit codes used when the WithExitCodes option is not used.
+var DefaultExitCodes = ExitCodes{
+	Startup: ExitCodeStartup,
+	Runtime: ExitCodeRuntime,
+	Unclean: ExitCodeUnclean,
+	Signal:  ExitCodeSignal,
+}
+
+// ExitCoder is implemented by errors carrying their own exit code.
+// Main uses it for the startup, main function and component errors.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// exitCodeMapping maps the errors matching target to an exit code.
+type exitCodeMapping struct {
+	target error
+	code   int
+}
+
+// WithExitCodes is an Option that replaces the exit codes used by Main.
+func WithExitCodes(codes ExitCodes) Option {
+	return func(e *Engine) {
+		e.exitCodes = codes
+	}
+}
+
+// ExitCodeFor is an Option that makes Main exit with code when the startup, the main function
+// or a component fails with an error matching target, according to errors.Is.
+// The mappings are checked in order, before the ExitCoder interface.
+func ExitCodeFor(target error, code int) Option {
+	return func(e *Engine) {
+		e.exitCodeMappings = append(e.exitCodeMappings, exitCodeMapping{target: target, code: code})
+	}
+}
+
+// Main is the entry point of an application: it creates the Engine with the options, starts it,
+// runs fn and waits for the graceful shutdown. In CLI and job mode, the application is shut down
+// once fn returns. Otherwise a nil error from fn leaves the application running until it is
+// shut down, e.g. by a signal, while an error shuts it down immediately.
+// Main then flushes the logger and exits the process with the code matching the outcome, see ExitCodes.
+//
+//	func main() {
+//		application.Main(func(app application.Application) error {
+//			app.Logger().Info("Application is running...")
+//			return nil
+//		}, application.AppName("my-service"), zaplogger.SetZapLogger())
+//	}
+func Main(fn func(app Application) error, opts ...Option) {
+	Exit(run(fn, opts))
+}
+
+// run creates the Engine, runs fn and returns the exit code.
+func run(fn func(app Application) error, opts []Option) int {
+	engine, err := newEngine(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create application: %v\n", err)
+		return engine.exitCode(err, engine.exitCodes.Startup)
+	}
+
+	code := engine.run(fn)
+
+	if engine.logger != nil {
+		engine.logger.Close()
+	}
+
+	return code
+}
+
+// run starts the engine, runs fn, waits for the shutdown and returns the exit code.
+func (e *Engine) run(fn func(app Application) error) int {
+	if err := e.Start(e.ctx); err != nil {
+		if e.ReceivedSignal() != nil {
+			return e.exitCodes.Signal
+		}
+		e.logError("Application failed to start", err)
+		return e.exitCode(err, e.exitCodes.Startup)
+	}
+
+	runErr := fn(e)
+	if runErr != nil {
+		e.logError("Application failed", runErr)
+	}
+	if runErr != nil || e.mode.shutsDownOnReturn() {
+		e.cancel()
+	}
+
+	_, shutdownErr := e.gracefull.Wait()
+
+	switch {
+	case runErr != nil:
+		return e.exitCode(runErr, e.exitCodes.Runtime)
+	case e.failure.Load() != nil:
+		return e.exitCode(*e.failure.Load(), e.exitCodes.Runtime)
+	case shutdownErr != nil:
+		e.logError("Application shut down uncleanly", shutdownErr)
+		return e.exitCodes.Unclean
+	case e.ReceivedSignal() != nil:
+		return e.exitCodes.Signal
+	default:
+		return ExitCodeOK
+	}
+}
+
+// exitCode returns the exit code mapped to err, or fallback if there is none.
+func (e *Engine) exitCode(err error, fallback int) int {
+	for _, m := range e.exitCodeMappings {
+		if errors.Is(err, m.target) {
+			return m.code
+		}
+	}
+
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+
+	return fallback
+}
+
+// logError logs an error, on the standard error if there is no logger.
+func (e *Engine) logError(msg string, err error) {
+	if e.logger == nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
+		return
+	}
+	e.logger.Error(msg, map[string]any{"error": err})
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RunMode is the way the application runs, which the logger options and the shutdown adapt to.
+type RunMode int
+
+// Run modes of the application.
+const (
+	// ModeService is a long-running process, shut down by a signal. It is the default.
+	ModeService RunMode = iota
+	// ModeCLI is a command-line tool: logs are human-readable, the application is shut down
+	// once the main function returns and a second Ctrl+C forces the exit.
+	ModeCLI
+	// ModeJob is a one-shot process, e.g. a batch or a migration,
+	// shut down once the main function returns.
+	ModeJob
+	// ModeTest is an application running in tests: logs are discarded and signals are not handled.
+	ModeTest
+)
+
+// String returns the name of the run mode.
+func (m RunMode) String() string {
+	switch m {
+	case ModeService:
+		return "service"
+	case ModeCLI:
+		return "cli"
+	case ModeJob:
+		return "job"
+	case ModeTest:
+		return "test"
+	default:
+		return fmt.Sprintf("RunMode(%d)", int(m))
+	}
+}
+
+// ParseRunMode returns the run mode named s, as returned by RunMode.String, ignoring case.
+func ParseRunMode(s string) (RunMode, error) {
+	for _, m := range []RunMode{ModeService, ModeCLI, ModeJob, ModeTest} {
+		if strings.EqualFold(s, m.String()) {
+			return m, nil
+		}
+	}
+	return ModeService, fmt.Errorf("invalid run mode %q: expected service, cli, job or test", s)
+}
+
+// WithRunMode is an Option that sets the run mode of the application.
+// It must be passed before the logger options, which depend on it:
+// changing the run mode once a logger is set fails.
+func WithRunMode(mode RunMode) Option {
+	return OptionE(func(e *Engine) error {
+		return e.setRunMode(mode)
+	}).Option()
+}
+
+// WithCLIMode is an Option that sets the application to run in CLI mode, see ModeCLI.
+func WithCLIMode() Option {
+	return WithRunMode(ModeCLI)
+}
+
+// RunMode returns the run mode of the application.
+func (e *Engine) RunMode() RunMode {
+	return e.mode
+}
+
+// CLIMode checks if the application is running in CLI mode.
+func (e *Engine) CLIMode() bool {
+	return e.mode == ModeCLI
+}
+
+// setRunMode sets the run mode, unless it changes the mode a logger was already set for.
+func (e *Engine) setRunMode(mode RunMode) error {
+	if e.logger != nil && mode != e.mode {
+		return fmt.Errorf("cannot set the run mode to %s: a logger is already set for the %s mode, pass the run mode before the logger options", mode, e.mode)
+	}
+
+	e.mode = mode
+	return nil
+}
+
+// shutsDownOnReturn reports whether Main shuts the application down once the main function returns.
+func (m RunMode) shutsDownOnReturn() bool {
+	return m == ModeCLI || m == ModeJob
+}
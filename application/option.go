@@ -2,16 +2,31 @@
 package application
 
 import (
-	"context"
+	"errors"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/lifecycle"
 )
 
 // Option is a function that configures the Engine.
 type Option func(*Engine)
 
-// WithCLIMode is an option to set the application to run in CLI mode.
-func WithCLIMode() Option {
+// OptionE is a function that configures the Engine and can fail.
+// It is passed to New as an Option, see OptionE.Option.
+type OptionE func(*Engine) error
+
+// Option returns an Option applying o. The errors returned by o are aggregated and returned by New:
+//
+//	func WithDatabase(dsn string) application.Option {
+//		return application.OptionE(func(e *application.Engine) error {
+//			...
+//		}).Option()
+//	}
+func (o OptionE) Option() Option {
 	return func(e *Engine) {
-		e.ctx = context.WithValue(e.ctx, "cli_mode", true)
+		if err := o(e); err != nil {
+			e.errs = append(e.errs, err)
+		}
 	}
 }
 
@@ -54,3 +69,28 @@ func Env(env string) Option {
 		e.appEnv = env
 	}
 }
+
+// ShutdownTimeout is an Option that sets the deadline of the graceful shutdown.
+// The shutdown hooks still running at the deadline are abandoned, so that a hung hook
+// cannot prevent the application from exiting.
+// It fails if the lifecycle manager of the Engine does not support timeouts.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return OptionE(func(e *Engine) error {
+		g, ok := e.gracefull.(interface{ SetTimeout(time.Duration) })
+		if !ok {
+			return errors.New("the lifecycle manager does not support shutdown timeouts")
+		}
+
+		g.SetTimeout(timeout)
+		return nil
+	}).Option()
+}
+
+// WithComponents is an Option that adds long-running components to the Engine, see Engine.Add.
+// It is the way to add components when using Main, as the application is already started when fn runs.
+// It must be passed after the logger option, so that the components receive a named logger.
+func WithComponents(components ...lifecycle.Component) Option {
+	return OptionE(func(e *Engine) error {
+		return e.Add(components...)
+	}).Option()
+}
@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+)
+
+// ExitCodeForced is the exit code used when a second terminating signal forces the exit.
+const ExitCodeForced = 130
+
+// Exit is a hook for os.Exit, can be replaced in tests.
+var Exit = os.Exit
+
+// Signals is an Option that sets the signals triggering the graceful shutdown.
+// The default is os.Interrupt and syscall.SIGTERM. Without signals, none triggers the shutdown.
+func Signals(signals ...os.Signal) Option {
+	return func(e *Engine) {
+		e.signals = signals
+	}
+}
+
+// OnSignal is an Option that runs handler whenever sig is received, without shutting the application down,
+// e.g. SIGHUP to reload the configuration or SIGUSR1 to toggle the log level.
+// A signal with a handler no longer triggers the graceful shutdown.
+// The handlers run one at a time, on the goroutine listening to the signals.
+func OnSignal(sig os.Signal, handler func(os.Signal)) Option {
+	return func(e *Engine) {
+		if e.signalHandlers == nil {
+			e.signalHandlers = make(map[os.Signal][]func(os.Signal))
+		}
+		e.signalHandlers[sig] = append(e.signalHandlers[sig], handler)
+	}
+}
+
+// ForceExitOnSecondSignal is an Option that exits immediately with ExitCodeForced
+// when a terminating signal is received while the graceful shutdown is in progress,
+// so that an operator pressing Ctrl+C twice is not stuck behind a slow hook.
+// It is always enabled in CLI mode.
+func ForceExitOnSecondSignal() Option {
+	return func(e *Engine) {
+		e.forceExit = true
+	}
+}
+
+// DumpGoroutines returns a signal handler writing the stack traces of all goroutines to w,
+// to be used with OnSignal, e.g. OnSignal(syscall.SIGQUIT, DumpGoroutines(os.Stderr)).
+func DumpGoroutines(w io.Writer) func(os.Signal) {
+	return func(sig os.Signal) {
+		buf := make([]byte, 1<<20)
+		for {
+			n := runtime.Stack(buf, true)
+			if n < len(buf) {
+				buf = buf[:n]
+				break
+			}
+			buf = make([]byte, 2*len(buf))
+		}
+
+		fmt.Fprintf(w, "Received %s, dumping goroutines:\n%s\n", sig, buf)
+	}
+}
+
+// defaultSignals are the signals triggering the graceful shutdown when the Signals option is not used.
+var defaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// handleSignals listens to the signals until the graceful shutdown is complete.
+// The first terminating signal cancels ctx, which triggers the graceful shutdown.
+// Unless ForceExitOnSecondSignal is used, the terminating signals are then released
+// and get their default behavior back.
+func (e *Engine) handleSignals(ctx context.Context) {
+	var terminating, handled chan os.Signal
+	forceExit := e.forceExit || e.mode == ModeCLI
+
+	var terminatingSignals []os.Signal
+	for _, sig := range e.signals {
+		if _, ok := e.signalHandlers[sig]; !ok {
+			terminatingSignals = append(terminatingSignals, sig)
+		}
+	}
+	// signal.Notify without signals would relay every signal.
+	if len(terminatingSignals) > 0 {
+		terminating = make(chan os.Signal, 1)
+		signal.Notify(terminating, terminatingSignals...)
+	}
+
+	if len(e.signalHandlers) > 0 {
+		handled = make(chan os.Signal, 1)
+		for sig := range e.signalHandlers {
+			signal.Notify(handled, sig)
+		}
+	}
+
+	if terminating == nil && handled == nil {
+		return
+	}
+
+	go func() {
+		defer signal.Stop(terminating)
+		defer signal.Stop(handled)
+
+		for {
+			select {
+			case sig := <-handled:
+				for _, handler := range e.signalHandlers[sig] {
+					handler(sig)
+				}
+			case sig := <-terminating:
+				if ctx.Err() != nil && forceExit {
+					fmt.Fprintf(os.Stderr, "Received %s during shutdown, forcing exit\n", sig)
+					Exit(ExitCodeForced)
+					return
+				}
+
+				e.received.CompareAndSwap(nil, &sig)
+				e.cancel()
+
+				if !forceExit {
+					signal.Stop(terminating)
+					terminating = nil
+				}
+			case <-e.gracefull.Done():
+				return
+			}
+		}
+	}()
+}
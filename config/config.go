@@ -0,0 +1,291 @@
+// Package config populates configuration structs from configuration files and the environment,
+// according to their tags.
+//
+//	type Config struct {
+//		DatabaseURL string        `env:"DB_URL" required:"true"`
+//		Timeout     time.Duration `env:"TIMEOUT" default:"5s"`
+//		Hosts       []string      `env:"HOSTS" default:"a,b"`
+//		Cache       CacheConfig   `env:"CACHE"` // CACHE_SIZE, CACHE_TTL, ...
+//	}
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	apperrors "github.com/deadelus/go-clean-app/v2/errors"
+)
+
+// ErrMissing is wrapped by the errors of the required fields without value.
+var ErrMissing = errors.New(apperrors.ErrMissingConfig)
+
+// ErrInvalid is wrapped by the errors of the fields whose value cannot be parsed.
+var ErrInvalid = errors.New("invalid configuration")
+
+// FieldError is the error of a single field of a configuration struct.
+type FieldError struct {
+	// Field is the path of the field in the struct, e.g. "Database.URL".
+	Field string
+	// Key is the environment variable of the field, if any.
+	Key string
+	// Err wraps ErrMissing, ErrInvalid or the error of the SecretProvider.
+	Err error
+}
+
+// Error returns the field, its variable and the error.
+func (e *FieldError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %v", e.Field, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a Loader.
+type Option func(*Loader)
+
+// WithPrefix prepends prefix to every variable name, e.g. WithPrefix("BILLING_") reads BILLING_DB_URL for `env:"DB_URL"`.
+func WithPrefix(prefix string) Option {
+	return func(l *Loader) {
+		l.prefix = prefix
+	}
+}
+
+// WithEnv enables the configuration files of LayerFiles(env), e.g. "config.yaml" and "config.production.yaml",
+// read from the current directory unless WithDir is used. Missing files are skipped.
+func WithEnv(env string) Option {
+	return func(l *Loader) {
+		l.env = env
+	}
+}
+
+// WithDir sets the directory of the files enabled by WithEnv.
+func WithDir(dir string) Option {
+	return func(l *Loader) {
+		l.dir = dir
+	}
+}
+
+// WithFiles adds configuration files, e.g. the one given with --config, see PathFromArgs.
+// They are loaded after the files enabled by WithEnv, in order, and must exist.
+// Files ending with .json are parsed as JSON, the others as YAML.
+func WithFiles(paths ...string) Option {
+	return func(l *Loader) {
+		l.files = append(l.files, paths...)
+	}
+}
+
+// WithLookup replaces the function reading the environment, os.LookupEnv by default.
+func WithLookup(lookup func(key string) (string, bool)) Option {
+	return func(l *Loader) {
+		l.lookup = lookup
+	}
+}
+
+// Loader populates configuration structs.
+//
+// The value of a field is taken from the first of these sources holding it:
+//
+//  1. the variable named by its env tag; empty variables are reported as unset,
+//  2. the file named by the variable suffixed with _FILE, e.g. DB_PASSWORD_FILE, as Docker
+//     and Kubernetes secrets are mounted,
+//  3. the SecretProvider, for the fields tagged with secret:"<name>",
+//  4. the configuration files, the last file holding the field winning: the files of WithFiles
+//     in reverse order, then "config.<env>" and "config" with WithEnv,
+//  5. its default tag.
+//
+// A field tagged required:"true" without value is an error. The fields of a nested struct
+// are read recursively, the env tag of the struct, if any, prefixing their variables with an underscore.
+// A nil pointer to a nested struct is an optional section: it is only allocated, and its required
+// fields checked, if at least one of its fields has a value, from any source including its default.
+// In the files, a nested struct is a mapping and the key of a field is the name of its yaml
+// or json tag, or else its env tag or its name, lowercased, e.g. "db_url" for `env:"DB_URL"`.
+// Lists and mappings of the files replace the value of a field as a whole.
+//
+// Supported types are strings, booleans, integers, floats, time.Duration,
+// the types implementing encoding.TextUnmarshaler, pointers to them,
+// slices of comma-separated values and maps of comma-separated key:value pairs.
+type Loader struct {
+	prefix  string
+	lookup  func(key string) (string, bool)
+	env     string
+	dir     string
+	files   []string
+	secrets SecretProvider
+	ctx     context.Context
+	sources map[string]string
+}
+
+// New creates a Loader with the options.
+func New(opts ...Option) *Loader {
+	l := &Loader{lookup: os.LookupEnv, ctx: context.Background()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load populates cfg, a pointer to a struct.
+// Every field is loaded, even after a failure, and the errors are aggregated and returned
+// as *FieldError values joined with errors.Join.
+func Load(cfg any, opts ...Option) error {
+	return New(opts...).Load(cfg)
+}
+
+// Load populates cfg, a pointer to a struct, see the package Load function.
+func (l *Loader) Load(cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("failed to load configuration: %T is not a pointer to a struct", cfg)
+	}
+
+	layers, err := l.readLayers()
+	if err != nil {
+		return err
+	}
+
+	l.sources = make(map[string]string)
+
+	var errs []error
+	l.loadStruct(v.Elem(), "", l.prefix, layers, &errs)
+	return errors.Join(errs...)
+}
+
+// Sources returns the source of the value of each field set by the last call to Load,
+// by field path: "env:<variable>", "file:<path>" or "default".
+func (l *Loader) Sources() map[string]string {
+	return l.sources
+}
+
+// loadStruct loads the fields of a struct, path and prefix being those of the struct itself.
+// It reports whether any field, even invalid, has a value.
+func (l *Loader) loadStruct(v reflect.Value, path, prefix string, layers []layer, errs *[]error) bool {
+	found := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fieldPath := sf.Name
+		if path != "" {
+			fieldPath = path + "." + sf.Name
+		}
+		fv := v.Field(i)
+		key, hasKey := sf.Tag.Lookup("env")
+
+		if isNested(sf.Type) {
+			nestedPrefix := prefix
+			if hasKey && key != "" {
+				nestedPrefix = prefix + key + "_"
+			}
+			nestedLayers := narrow(layers, fileKey(sf))
+			if sf.Type.Kind() == reflect.Pointer && fv.IsNil() {
+				// An optional section is only allocated, and its required fields checked, if it has a value
+				section := reflect.New(sf.Type.Elem())
+				var sectionErrs []error
+				if l.loadStruct(section.Elem(), fieldPath, nestedPrefix, nestedLayers, &sectionErrs) {
+					fv.Set(section)
+					*errs = append(*errs, sectionErrs...)
+					found = true
+				}
+				continue
+			}
+			if sf.Type.Kind() == reflect.Pointer {
+				fv = fv.Elem()
+			}
+			found = l.loadStruct(fv, fieldPath, nestedPrefix, nestedLayers, errs) || found
+			continue
+		}
+
+		if hasKey && key != "" {
+			key = prefix + key
+		}
+		found = l.loadField(fv, sf, fieldPath, key, layers, errs) || found
+	}
+	return found
+}
+
+// loadField loads a single field from the variable key, the files or its default, and records its source.
+// It reports whether the field has a value, even invalid.
+func (l *Loader) loadField(fv reflect.Value, sf reflect.StructField, path, key string, layers []layer, errs *[]error) bool {
+	var raw any
+	var source string
+	var found bool
+
+	if key != "" {
+		if v, ok := l.lookup(key); ok && v != "" {
+			raw, source, found = v, "env:"+key, true
+		}
+	}
+
+	if !found && key != "" {
+		v, src, ok, err := l.lookupFileEnv(key)
+		if err != nil {
+			*errs = append(*errs, &FieldError{Field: path, Key: key + "_FILE", Err: err})
+			return true
+		}
+		raw, source, found = v, src, ok
+	}
+
+	if !found {
+		v, src, ok, err := l.lookupSecret(sf.Tag.Get("secret"))
+		if err != nil {
+			*errs = append(*errs, &FieldError{Field: path, Key: key, Err: err})
+			return true
+		}
+		raw, source, found = v, src, ok
+	}
+
+	if !found {
+		if v, file, ok := lookupLayers(layers, fileKey(sf)); ok {
+			raw, source, found = v, "file:"+file, true
+		}
+	}
+
+	if !found {
+		if v, ok := sf.Tag.Lookup("default"); ok {
+			raw, source, found = v, "default", true
+		}
+	}
+
+	if !found {
+		if sf.Tag.Get("required") == "true" {
+			*errs = append(*errs, &FieldError{Field: path, Key: key, Err: ErrMissing})
+		}
+		return false
+	}
+
+	if err := setRaw(fv, raw); err != nil {
+		*errs = append(*errs, &FieldError{Field: path, Key: key, Err: fmt.Errorf("%w: %v", ErrInvalid, err)})
+		return true
+	}
+	l.sources[path] = source
+	return true
+}
+
+// isNested reports whether a field of type t is a nested struct, loaded field by field.
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isTextUnmarshaler(t)
+}
+
+// splitList splits a comma-separated list, ignoring the spaces around the items.
+func splitList(s string) []string {
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
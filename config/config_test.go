@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type cacheConfig struct {
+	Size int           `env:"SIZE" default:"128"`
+	TTL  time.Duration `env:"TTL" required:"true"`
+}
+
+type testConfig struct {
+	URL      string            `env:"DB_URL" required:"true"`
+	Port     uint16            `env:"PORT" default:"8080"`
+	Debug    bool              `env:"DEBUG"`
+	Ratio    float64           `env:"RATIO" default:"0.5"`
+	Timeout  time.Duration     `env:"TIMEOUT" default:"5s"`
+	Hosts    []string          `env:"HOSTS" default:"a, b"`
+	Ports    []int             `env:"PORTS"`
+	Labels   map[string]string `env:"LABELS"`
+	Level    level             `env:"LEVEL" default:"info"`
+	IP       net.IP            `env:"IP"`
+	MaxConns *int              `env:"MAX_CONNS"`
+	Cache    cacheConfig       `env:"CACHE"`
+	Shared   *cacheConfig
+	Untagged string
+	internal string
+}
+
+// env returns a lookup function reading vars.
+func env(vars map[string]string) config.Option {
+	return config.WithLookup(func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("values and defaults", func(t *testing.T) {
+		var cfg testConfig
+		err := config.Load(&cfg, env(map[string]string{
+			"DB_URL":    "postgres://localhost",
+			"DEBUG":     "true",
+			"PORTS":     "80,443",
+			"LABELS":    "team:core, tier: 1",
+			"LEVEL":     "DEBUG",
+			"IP":        "10.0.0.1",
+			"MAX_CONNS": "10",
+			"CACHE_TTL": "1m",
+			"TTL":       "2m",
+			"PORT":      "",
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, "postgres://localhost", cfg.URL)
+		assert.Equal(t, uint16(8080), cfg.Port)
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, 0.5, cfg.Ratio)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		assert.Equal(t, []int{80, 443}, cfg.Ports)
+		assert.Equal(t, map[string]string{"team": "core", "tier": "1"}, cfg.Labels)
+		assert.Equal(t, level(0), cfg.Level)
+		assert.Equal(t, "10.0.0.1", cfg.IP.String())
+		require.NotNil(t, cfg.MaxConns)
+		assert.Equal(t, 10, *cfg.MaxConns)
+		assert.Equal(t, cacheConfig{Size: 128, TTL: time.Minute}, cfg.Cache)
+		require.NotNil(t, cfg.Shared)
+		assert.Equal(t, cacheConfig{Size: 128, TTL: 2 * time.Minute}, *cfg.Shared)
+	})
+
+	t.Run("every error is reported", func(t *testing.T) {
+		var cfg testConfig
+		err := config.Load(&cfg, env(map[string]string{
+			"PORT":  "99999",
+			"LEVEL": "verbose",
+			"PORTS": "80,http",
+		}))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, config.ErrMissing)
+		assert.ErrorIs(t, err, config.ErrInvalid)
+
+		var fieldErrs []string
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var fieldErr *config.FieldError
+			require.ErrorAs(t, e, &fieldErr)
+			fieldErrs = append(fieldErrs, fieldErr.Field)
+		}
+		assert.Equal(t, []string{"URL", "Port", "Ports", "Level", "Cache.TTL", "Shared.TTL"}, fieldErrs)
+		assert.Contains(t, err.Error(), "URL (DB_URL): missing configuration")
+		assert.Contains(t, err.Error(), "Ports (PORTS): invalid configuration: item 1")
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		var cfg cacheConfig
+		err := config.New(config.WithPrefix("APP_"), env(map[string]string{"APP_TTL": "1s", "TTL": "2s"})).Load(&cfg)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, cfg.TTL)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("TTL", "3s")
+		var cfg cacheConfig
+		require.NoError(t, config.Load(&cfg))
+		assert.Equal(t, 3*time.Second, cfg.TTL)
+	})
+
+	t.Run("optional section", func(t *testing.T) {
+		type tlsConfig struct {
+			Cert string `env:"CERT" required:"true"`
+			Key  string `env:"KEY" required:"true"`
+		}
+		type serverConfig struct {
+			TLS *tlsConfig `env:"TLS"`
+		}
+
+		var absent serverConfig
+		require.NoError(t, config.Load(&absent, env(nil)))
+		assert.Nil(t, absent.TLS)
+
+		var present serverConfig
+		require.NoError(t, config.Load(&present, env(map[string]string{"TLS_CERT": "cert.pem", "TLS_KEY": "key.pem"})))
+		require.NotNil(t, present.TLS)
+		assert.Equal(t, tlsConfig{Cert: "cert.pem", Key: "key.pem"}, *present.TLS)
+
+		var partial serverConfig
+		err := config.Load(&partial, env(map[string]string{"TLS_CERT": "cert.pem"}))
+		assert.ErrorIs(t, err, config.ErrMissing)
+		assert.ErrorContains(t, err, "TLS.Key (TLS_KEY)")
+	})
+
+	t.Run("not a pointer to a struct", func(t *testing.T) {
+		var cfg testConfig
+		assert.Error(t, config.Load(cfg))
+		assert.Error(t, config.Load((*testConfig)(nil)))
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		var cfg struct {
+			C chan int `env:"C" default:"1"`
+		}
+		err := config.Load(&cfg)
+		assert.ErrorIs(t, err, config.ErrInvalid)
+		assert.Contains(t, err.Error(), "unsupported type chan int")
+	})
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileExtensions are the extensions tried, in order, for each layer of LayerFiles.
+var fileExtensions = []string{".yaml", ".yml", ".json"}
+
+// LayerFiles returns the base names of the configuration files loaded with WithEnv,
+// in loading order: "config" and "config.<env>". Each one is resolved to the first
+// existing file among the .yaml, .yml and .json extensions.
+func LayerFiles(env string) []string {
+	return []string{"config", "config." + env}
+}
+
+// PathFromArgs returns the value of the --config flag in args, e.g. os.Args[1:],
+// given as "--config path", "--config=path" or with a single dash, or "" if there is none.
+func PathFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// layer holds the values read from a configuration file.
+type layer struct {
+	source string
+	values map[string]any
+}
+
+// readLayers reads the layered files and then the explicit files.
+// The layered files are optional, the explicit ones must exist.
+func (l *Loader) readLayers() ([]layer, error) {
+	var layers []layer
+
+	if l.env != "" {
+		for _, base := range LayerFiles(l.env) {
+			for _, ext := range fileExtensions {
+				path := filepath.Join(l.dir, base+ext)
+				values, err := readFile(path)
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				layers = append(layers, layer{source: path, values: values})
+				break
+			}
+		}
+	}
+
+	for _, path := range l.files {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer{source: path, values: values})
+	}
+
+	return layers, nil
+}
+
+// readFile parses a YAML or JSON file, according to its extension.
+func readFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file %s: %w", path, err)
+	}
+
+	values := make(map[string]any)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	} else {
+		err = yaml.Unmarshal(content, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// fileKey returns the key of a field in the configuration files: the name of its yaml or json tag,
+// or else its env tag or its name, lowercased. Keys are matched regardless of case.
+func fileKey(sf reflect.StructField) string {
+	for _, tag := range []string{"yaml", "json"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	if key := sf.Tag.Get("env"); key != "" {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(sf.Name)
+}
+
+// lookupKey returns the value of key in values, regardless of case.
+func lookupKey(values map[string]any, key string) (any, bool) {
+	if v, ok := values[key]; ok {
+		return v, true
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// narrow returns the layers of a nested struct stored under key.
+func narrow(layers []layer, key string) []layer {
+	var nested []layer
+	for _, ly := range layers {
+		if v, ok := lookupKey(ly.values, key); ok {
+			if m, ok := v.(map[string]any); ok {
+				nested = append(nested, layer{source: ly.source, values: m})
+			}
+		}
+	}
+	return nested
+}
+
+// lookupLayers returns the value of key in the last layer holding it, and the source of this layer.
+func lookupLayers(layers []layer, key string) (any, string, bool) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if v, ok := lookupKey(layers[i].values, key); ok && v != nil {
+			return v, layers[i].source, true
+		}
+	}
+	return nil, "", false
+}
+
+// setRaw stores a value read from a file in v: lists and mappings are stored item by item,
+// the other values are parsed from their text representation.
+func setRaw(v reflect.Value, raw any) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setRaw(v.Elem(), raw)
+	}
+
+	switch r := raw.(type) {
+	case string:
+		return setValue(v, r)
+	case time.Time:
+		return setValue(v, r.Format(time.RFC3339Nano))
+	case []any:
+		if v.Kind() != reflect.Slice || isTextUnmarshaler(v.Type()) {
+			return fmt.Errorf("unexpected list for %s", v.Type())
+		}
+		slice := reflect.MakeSlice(v.Type(), len(r), len(r))
+		for i, item := range r {
+			if err := setRaw(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	case map[string]any:
+		if v.Kind() != reflect.Map {
+			return fmt.Errorf("unexpected mapping for %s", v.Type())
+		}
+		m := reflect.MakeMap(v.Type())
+		for k, item := range r {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := setValue(key, k); err != nil {
+				return fmt.Errorf("key %q: %w", k, err)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setRaw(elem, item); err != nil {
+				return fmt.Errorf("value of %q: %w", k, err)
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+		return nil
+	default:
+		return setValue(v, fmt.Sprint(r))
+	}
+}
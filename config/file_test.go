@@ -0,0 +1,125 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fileConfig struct {
+	URL     string            `env:"DB_URL" required:"true"`
+	Port    int               `env:"PORT" default:"8080"`
+	Timeout time.Duration     `yaml:"request_timeout" default:"5s"`
+	Hosts   []string          `env:"HOSTS"`
+	Labels  map[string]string `env:"LABELS"`
+	Cache   struct {
+		Size int `env:"SIZE"`
+		TTL  time.Duration
+	} `env:"CACHE"`
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Files(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", `
+db_url: postgres://base
+port: 9000
+request_timeout: 10s
+hosts: [a, b]
+labels:
+  team: core
+cache:
+  size: 64
+  ttl: 1m
+`)
+	writeFile(t, dir, "config.production.json", `{"DB_URL": "postgres://prod", "cache": {"size": 1000000}}`)
+	explicit := writeFile(t, dir, "explicit.yml", "labels: {tier: \"1\"}\n")
+
+	t.Run("layers and environment", func(t *testing.T) {
+		loader := config.New(
+			config.WithEnv("production"),
+			config.WithDir(dir),
+			config.WithFiles(explicit),
+			env(map[string]string{"PORT": "9001"}),
+		)
+
+		var cfg fileConfig
+		require.NoError(t, loader.Load(&cfg))
+
+		assert.Equal(t, "postgres://prod", cfg.URL)
+		assert.Equal(t, 9001, cfg.Port)
+		assert.Equal(t, 10*time.Second, cfg.Timeout)
+		assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		assert.Equal(t, map[string]string{"tier": "1"}, cfg.Labels)
+		assert.Equal(t, 1000000, cfg.Cache.Size)
+		assert.Equal(t, time.Minute, cfg.Cache.TTL)
+
+		assert.Equal(t, map[string]string{
+			"URL":        "file:" + filepath.Join(dir, "config.production.json"),
+			"Port":       "env:PORT",
+			"Timeout":    "file:" + filepath.Join(dir, "config.yaml"),
+			"Hosts":      "file:" + filepath.Join(dir, "config.yaml"),
+			"Labels":     "file:" + explicit,
+			"Cache.Size": "file:" + filepath.Join(dir, "config.production.json"),
+			"Cache.TTL":  "file:" + filepath.Join(dir, "config.yaml"),
+		}, loader.Sources())
+	})
+
+	t.Run("missing layer files are skipped", func(t *testing.T) {
+		var cfg fileConfig
+		loader := config.New(config.WithEnv("staging"), config.WithDir(t.TempDir()), env(nil))
+		err := loader.Load(&cfg)
+		assert.ErrorIs(t, err, config.ErrMissing)
+		assert.Equal(t, map[string]string{"Port": "default", "Timeout": "default"}, loader.Sources())
+	})
+
+	t.Run("explicit files must exist", func(t *testing.T) {
+		var cfg fileConfig
+		err := config.Load(&cfg, config.WithFiles(filepath.Join(dir, "missing.yaml")))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		bad := writeFile(t, t.TempDir(), "bad.json", "{")
+		var cfg fileConfig
+		err := config.Load(&cfg, config.WithFiles(bad))
+		assert.ErrorContains(t, err, "failed to parse configuration file")
+	})
+
+	t.Run("unexpected list", func(t *testing.T) {
+		bad := writeFile(t, t.TempDir(), "bad.yaml", "db_url: [a, b]\n")
+		var cfg fileConfig
+		err := config.Load(&cfg, config.WithFiles(bad), env(nil))
+		assert.ErrorIs(t, err, config.ErrInvalid)
+	})
+}
+
+func TestPathFromArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"--config", "a.yaml"}, "a.yaml"},
+		{[]string{"-v", "--config=b.yaml"}, "b.yaml"},
+		{[]string{"-config", "c.yaml"}, "c.yaml"},
+		{[]string{"--", "--config", "d.yaml"}, ""},
+		{[]string{"config", "e.yaml"}, ""},
+		{[]string{"--config"}, ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, config.PathFromArgs(tt.args), tt.args)
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ReportEntry is the effective value of a configuration field.
+type ReportEntry struct {
+	// Field is the path of the field in the struct, e.g. "Database.URL".
+	Field string `json:"field"`
+	// Value is the value of the field, redacted for the secrets.
+	Value string `json:"value"`
+	// Source is the source of the value, see Loader.Sources, or "" if the field has no value.
+	Source string `json:"source,omitempty"`
+}
+
+// Report is the effective configuration, to be logged at startup or attached to a support ticket.
+// The Secret fields and the fields tagged redact:"true" are redacted.
+type Report []ReportEntry
+
+// NewReport returns the report of cfg, a configuration struct or a pointer to it,
+// with the sources of its values as returned by Loader.Sources.
+func NewReport(cfg any, sources map[string]string) Report {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var report Report
+	report.addStruct(v, "", sources)
+	return report
+}
+
+// addStruct adds the fields of a struct to the report.
+func (r *Report) addStruct(v reflect.Value, path string, sources map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fieldPath := sf.Name
+		if path != "" {
+			fieldPath = path + "." + sf.Name
+		}
+		fv := v.Field(i)
+
+		if isNested(sf.Type) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			r.addStruct(fv, fieldPath, sources)
+			continue
+		}
+
+		*r = append(*r, ReportEntry{
+			Field:  fieldPath,
+			Value:  formatValue(fv, sf.Tag.Get("redact") == "true"),
+			Source: sources[fieldPath],
+		})
+	}
+}
+
+// formatValue returns the text representation of a field, redacted if needed.
+func formatValue(fv reflect.Value, redact bool) string {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return "<nil>"
+		}
+		fv = fv.Elem()
+	}
+	if redact && !fv.IsZero() {
+		return Redacted
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// String returns the report as aligned "field = value (source)" lines.
+func (r Report) String() string {
+	width := 0
+	for _, e := range r {
+		width = max(width, len(e.Field))
+	}
+
+	var b strings.Builder
+	for _, e := range r {
+		fmt.Fprintf(&b, "%-*s = %s", width, e.Field, e.Value)
+		if e.Source != "" {
+			fmt.Fprintf(&b, " (%s)", e.Source)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted is the text replacing the value of a Secret wherever it is printed, encoded or logged.
+const Redacted = "[REDACTED]"
+
+// ErrSecretNotFound is returned by a SecretProvider when it has no secret with the requested name.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Secret is a configuration value that must not be disclosed, such as a password or an API key.
+// It redacts itself in String, fmt verbs, JSON, text encodings and zap fields;
+// only Value returns the actual value.
+//
+//	type Config struct {
+//		Password config.Secret `env:"DB_PASSWORD" required:"true"`
+//	}
+type Secret struct {
+	value string
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Value returns the actual value of the secret.
+func (s Secret) Value() string {
+	return s.value
+}
+
+// IsZero reports whether the secret is empty.
+func (s Secret) IsZero() bool {
+	return s.value == ""
+}
+
+// String returns Redacted, or "" if the secret is empty so that a missing secret can be told apart.
+func (s Secret) String() string {
+	if s.value == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// GoString returns the same as String, for the %#v verb.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// Format prints the secret redacted, whatever the verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		fmt.Fprintf(f, "%q", s.String())
+		return
+	}
+	fmt.Fprint(f, s.String())
+}
+
+// MarshalText returns the secret redacted.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// MarshalJSON returns the secret redacted.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// MarshalLogObject encodes the secret redacted in the zap fields.
+func (s Secret) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("secret", s.String())
+	return nil
+}
+
+// UnmarshalText sets the value of the secret.
+func (s *Secret) UnmarshalText(text []byte) error {
+	s.value = string(text)
+	return nil
+}
+
+// SecretProvider provides the values of the fields tagged with secret:"<name>",
+// e.g. from a vault or a cloud secret manager.
+type SecretProvider interface {
+	// GetSecret returns the secret named name, or an error wrapping ErrSecretNotFound.
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// FileSecretProvider is a SecretProvider reading each secret from the file named after it in Dir,
+// e.g. a directory of Kubernetes secrets or a fixture directory in tests.
+// The trailing newline of the files is ignored.
+type FileSecretProvider struct {
+	Dir string
+}
+
+// GetSecret returns the content of the file name in the directory.
+func (p FileSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.Dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// WithSecretProvider sets the provider of the fields tagged with secret:"<name>".
+func WithSecretProvider(provider SecretProvider) Option {
+	return func(l *Loader) {
+		l.secrets = provider
+	}
+}
+
+// WithContext sets the context given to the SecretProvider, context.Background() by default.
+func WithContext(ctx context.Context) Option {
+	return func(l *Loader) {
+		l.ctx = ctx
+	}
+}
+
+// lookupFileEnv reads the file named by the variable key+"_FILE", as Docker and Kubernetes
+// secrets are mounted, and returns its content without the trailing newline.
+func (l *Loader) lookupFileEnv(key string) (string, string, bool, error) {
+	path, ok := l.lookup(key + "_FILE")
+	if !ok || path == "" {
+		return "", "", false, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), "env:" + key + "_FILE", true, nil
+}
+
+// lookupSecret gets the secret named name from the SecretProvider, if any.
+func (l *Loader) lookupSecret(name string) (string, string, bool, error) {
+	if l.secrets == nil || name == "" {
+		return "", "", false, nil
+	}
+
+	value, err := l.secrets.GetSecret(l.ctx, name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	return value, "secret:" + name, true, nil
+}
@@ -0,0 +1,134 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/deadelus/go-clean-app/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSecret_Redaction(t *testing.T) {
+	s := config.NewSecret("hunter2")
+	assert.Equal(t, "hunter2", s.Value())
+	assert.False(t, s.IsZero())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%x", "%d"} {
+		assert.Equal(t, config.Redacted, fmt.Sprintf(format, s), format)
+	}
+	assert.Equal(t, `"[REDACTED]"`, fmt.Sprintf("%q", s))
+	assert.Equal(t, config.Redacted, s.String())
+
+	cfg := struct {
+		User     string
+		Password config.Secret
+	}{User: "admin", Password: s}
+	assert.NotContains(t, fmt.Sprintf("%+v", cfg), "hunter2")
+	assert.NotContains(t, fmt.Sprintf("%#v", cfg), "hunter2")
+
+	out, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"User": "admin", "Password": "[REDACTED]"}`, string(out))
+
+	text, err := s.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, config.Redacted, string(text))
+
+	var buf bytes.Buffer
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zap.InfoLevel))
+	logger.Info("connecting", zap.Any("password", s), zap.Any("config", cfg))
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), `"password":{"secret":"[REDACTED]"}`)
+
+	assert.Equal(t, "", config.Secret{}.String())
+	assert.True(t, config.Secret{}.IsZero())
+}
+
+// staticProvider is a SecretProvider returning fixed secrets.
+type staticProvider map[string]string
+
+func (p staticProvider) GetSecret(_ context.Context, name string) (string, error) {
+	if name == "broken" {
+		return "", errors.New("vault unavailable")
+	}
+	v, ok := p[name]
+	if !ok {
+		return "", config.ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func TestLoad_Secrets(t *testing.T) {
+	type secretConfig struct {
+		Password config.Secret `env:"DB_PASSWORD" required:"true"`
+		APIKey   config.Secret `env:"API_KEY" secret:"api-key"`
+		Token    config.Secret `secret:"token" default:"dev-token"`
+	}
+
+	dir := t.TempDir()
+	passwordFile := writeFile(t, dir, "password", "s3cret\n")
+
+	t.Run("file indirection and provider", func(t *testing.T) {
+		loader := config.New(
+			env(map[string]string{"DB_PASSWORD_FILE": passwordFile}),
+			config.WithSecretProvider(staticProvider{"api-key": "k3y"}),
+		)
+
+		var cfg secretConfig
+		require.NoError(t, loader.Load(&cfg))
+		assert.Equal(t, "s3cret", cfg.Password.Value())
+		assert.Equal(t, "k3y", cfg.APIKey.Value())
+		assert.Equal(t, "dev-token", cfg.Token.Value())
+		assert.Equal(t, map[string]string{
+			"Password": "env:DB_PASSWORD_FILE",
+			"APIKey":   "secret:api-key",
+			"Token":    "default",
+		}, loader.Sources())
+	})
+
+	t.Run("variable wins over file", func(t *testing.T) {
+		var cfg secretConfig
+		require.NoError(t, config.Load(&cfg, env(map[string]string{"DB_PASSWORD": "direct", "DB_PASSWORD_FILE": passwordFile})))
+		assert.Equal(t, "direct", cfg.Password.Value())
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		var cfg secretConfig
+		err := config.Load(&cfg, env(map[string]string{"DB_PASSWORD_FILE": filepath.Join(dir, "missing")}))
+		assert.ErrorIs(t, err, config.ErrInvalid)
+		assert.ErrorContains(t, err, "Password (DB_PASSWORD_FILE)")
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		type brokenConfig struct {
+			Key config.Secret `secret:"broken"`
+		}
+		var cfg brokenConfig
+		err := config.Load(&cfg, config.WithSecretProvider(staticProvider{}))
+		assert.ErrorContains(t, err, "failed to get secret broken: vault unavailable")
+	})
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "api-key", "k3y\r\n")
+	provider := config.FileSecretProvider{Dir: dir}
+
+	v, err := provider.GetSecret(context.Background(), "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "k3y", v)
+
+	_, err = provider.GetSecret(context.Background(), "missing")
+	assert.ErrorIs(t, err, config.ErrSecretNotFound)
+
+	_, err = provider.GetSecret(context.Background(), "../etc/passwd")
+	assert.ErrorContains(t, err, "invalid secret name")
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PollInterval is the interval at which the files are checked for changes by the stores
+// watched by the application, see Store.Watch.
+var PollInterval = 5 * time.Second
+
+// Validator is implemented by the configuration structs checking their own invariants.
+type Validator interface {
+	Validate() error
+}
+
+// Store holds the current snapshot of a configuration struct of type T and reloads it,
+// e.g. to change a log level or a rate limit without restarting.
+// A new snapshot is validated with Validate before it replaces the current one, and the subscribers
+// registered with OnChange are notified. It is safe for concurrent use.
+type Store[T any] struct {
+	current atomic.Pointer[T]
+
+	// reloadMu serializes the loads and reloads
+	reloadMu sync.Mutex
+
+	mu          sync.Mutex
+	loader      *Loader
+	sources     map[string]string
+	subscribers map[int]func(old, new T)
+	order       []int
+	nextID      int
+	onError     func(err error)
+	// pending are the changes not notified yet, notifying whether a goroutine is notifying them
+	pending   []change[T]
+	notifying bool
+}
+
+// change is a replacement of the snapshot of a Store.
+type change[T any] struct {
+	old, new *T
+}
+
+// NewStore creates an empty Store, populated by Load.
+func NewStore[T any]() *Store[T] {
+	s := &Store[T]{subscribers: make(map[int]func(old, new T))}
+	s.current.Store(new(T))
+	return s
+}
+
+// Load populates the store with a Loader created with opts, which is kept for the reloads.
+// The subscribers are not notified.
+func (s *Store[T]) Load(opts ...Option) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	loader := New(opts...)
+	cfg, err := load[T](loader)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.loader = loader
+	s.sources = loader.Sources()
+	s.mu.Unlock()
+
+	s.current.Store(cfg)
+	return nil
+}
+
+// Snapshot returns a pointer to the current snapshot, which must not be modified.
+func (s *Store[T]) Snapshot() any {
+	return s.current.Load()
+}
+
+// Get returns the current snapshot.
+func (s *Store[T]) Get() T {
+	return *s.current.Load()
+}
+
+// Sources returns the source of each field of the current snapshot, see Loader.Sources.
+func (s *Store[T]) Sources() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sources
+}
+
+// OnChange registers fn to be called with the previous and the new snapshot after each reload.
+// The subscribers are called in registration order, one reload at a time, without any lock held:
+// they may use the store, e.g. unsubscribe or reload it, in which case the new change is notified
+// once the current one is.
+// It returns a function unregistering fn.
+func (s *Store[T]) OnChange(fn func(old, new T)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = fn
+	s.order = append(s.order, id)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, id)
+		s.order = slices.DeleteFunc(s.order, func(i int) bool { return i == id })
+	}
+}
+
+// OnError sets the function called with the errors of the reloads triggered by Watch.
+func (s *Store[T]) OnError(fn func(err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onError = fn
+}
+
+// Reload loads a new snapshot and, if it is valid, replaces the current one and notifies the subscribers.
+// On failure, the current snapshot is kept and the error is returned.
+// The subscribers are notified before Reload returns, unless another change is being notified,
+// e.g. when a subscriber reloads the store: the new change is then notified right after it.
+func (s *Store[T]) Reload() error {
+	s.reloadMu.Lock()
+
+	s.mu.Lock()
+	loader := s.loader
+	s.mu.Unlock()
+
+	if loader == nil {
+		s.reloadMu.Unlock()
+		return fmt.Errorf("failed to reload configuration: the store is not loaded")
+	}
+
+	cfg, err := load[T](loader)
+	if err != nil {
+		s.reloadMu.Unlock()
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+
+	s.mu.Lock()
+	s.sources = loader.Sources()
+	s.pending = append(s.pending, change[T]{old: s.current.Swap(cfg), new: cfg})
+	notify := !s.notifying
+	s.notifying = true
+	s.mu.Unlock()
+
+	s.reloadMu.Unlock()
+
+	if notify {
+		s.notify()
+	}
+	return nil
+}
+
+// notify calls the subscribers with the pending changes, in order, until there is none left.
+func (s *Store[T]) notify() {
+	for {
+		s.mu.Lock()
+		if len(s.pending) == 0 {
+			s.notifying = false
+			s.mu.Unlock()
+			return
+		}
+		c := s.pending[0]
+		s.pending = s.pending[1:]
+		subscribers := make([]func(old, new T), 0, len(s.order))
+		for _, id := range s.order {
+			subscribers = append(subscribers, s.subscribers[id])
+		}
+		s.mu.Unlock()
+
+		for _, fn := range subscribers {
+			fn(*c.old, *c.new)
+		}
+	}
+}
+
+// Watch checks the configuration files every interval, PollInterval if it is not positive,
+// and reloads the store when one of them is created, modified or removed, until ctx is done.
+// Polling works on every file system, including the volumes mounted in containers.
+// The reload errors are passed to the function set with OnError.
+func (s *Store[T]) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = PollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := s.fingerprint()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := s.fingerprint()
+			if current == last {
+				continue
+			}
+			last = current
+
+			if err := s.Reload(); err != nil {
+				s.mu.Lock()
+				onError := s.onError
+				s.mu.Unlock()
+
+				if onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}
+}
+
+// fingerprint returns the size and modification time of the watched files.
+func (s *Store[T]) fingerprint() string {
+	s.mu.Lock()
+	loader := s.loader
+	s.mu.Unlock()
+
+	if loader == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, path := range loader.watchedFiles() {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:-;", path)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
+
+// watchedFiles returns the files the loader may read, existing or not.
+func (l *Loader) watchedFiles() []string {
+	var paths []string
+	if l.env != "" {
+		for _, base := range LayerFiles(l.env) {
+			for _, ext := range fileExtensions {
+				paths = append(paths, filepath.Join(l.dir, base+ext))
+			}
+		}
+	}
+	return append(paths, l.files...)
+}
+
+// load loads and validates a new configuration struct.
+func load[T any](l *Loader) (*T, error) {
+	cfg := new(T)
+	if err := l.Load(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
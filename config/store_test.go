@@ -0,0 +1,156 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type limits struct {
+	Rate  int    `env:"RATE" default:"10"`
+	Level string `env:"LEVEL" default:"info"`
+}
+
+func (l limits) Validate() error {
+	if l.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	vars := map[string]string{}
+	lookup := env(vars)
+
+	store := config.NewStore[limits]()
+	assert.Equal(t, limits{}, store.Get())
+	assert.Error(t, store.Reload())
+
+	require.NoError(t, store.Load(lookup))
+	assert.Equal(t, limits{Rate: 10, Level: "info"}, store.Get())
+	assert.Equal(t, "default", store.Sources()["Rate"])
+
+	type change struct{ old, new limits }
+	var changes []change
+	store.OnChange(func(old, new limits) { changes = append(changes, change{old, new}) })
+	var calls int
+	unsubscribe := store.OnChange(func(limits, limits) { calls++ })
+
+	t.Run("reload notifies the subscribers", func(t *testing.T) {
+		vars["RATE"] = "20"
+		require.NoError(t, store.Reload())
+
+		assert.Equal(t, limits{Rate: 20, Level: "info"}, store.Get())
+		assert.Equal(t, []change{{old: limits{Rate: 10, Level: "info"}, new: limits{Rate: 20, Level: "info"}}}, changes)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "env:RATE", store.Sources()["Rate"])
+	})
+
+	t.Run("invalid snapshot is rejected", func(t *testing.T) {
+		vars["RATE"] = "-1"
+		err := store.Reload()
+		assert.ErrorIs(t, err, config.ErrInvalid)
+		assert.ErrorContains(t, err, "rate must be positive")
+
+		vars["RATE"] = "abc"
+		assert.ErrorIs(t, store.Reload(), config.ErrInvalid)
+
+		assert.Equal(t, limits{Rate: 20, Level: "info"}, store.Get())
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		unsubscribe()
+		vars["RATE"] = "30"
+		require.NoError(t, store.Reload())
+		assert.Len(t, changes, 2)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("subscribers may use the store", func(t *testing.T) {
+		vars["RATE"] = "40"
+		var rates []int
+		var sources []string
+		var unsubscribeSelf func()
+		unsubscribeSelf = store.OnChange(func(old, new limits) {
+			rates = append(rates, new.Rate)
+			sources = append(sources, store.Sources()["Rate"])
+			if new.Rate == 40 {
+				vars["RATE"] = "50"
+				require.NoError(t, store.Reload())
+				return
+			}
+			unsubscribeSelf()
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			store.Reload()
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the reload")
+		}
+
+		assert.Equal(t, []int{40, 50}, rates, "the nested reload is notified after the current one")
+		assert.Equal(t, []string{"env:RATE", "env:RATE"}, sources)
+
+		vars["RATE"] = "60"
+		require.NoError(t, store.Reload())
+		assert.Equal(t, []int{40, 50}, rates, "the subscriber unsubscribed itself")
+	})
+
+	t.Run("initial load is validated", func(t *testing.T) {
+		vars["RATE"] = "0"
+		assert.ErrorIs(t, config.NewStore[limits]().Load(lookup), config.ErrInvalid)
+	})
+}
+
+func TestStore_Watch(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "rate: 5\n")
+
+	store := config.NewStore[limits]()
+	require.NoError(t, store.Load(config.WithEnv("test"), config.WithDir(dir), env(nil)))
+	assert.Equal(t, 5, store.Get().Rate)
+
+	changed := make(chan limits, 10)
+	store.OnChange(func(_, new limits) { changed <- new })
+	failed := make(chan error, 10)
+	store.OnError(func(err error) { failed <- err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.Watch(ctx, 10*time.Millisecond)
+
+	// Let the watcher take its first fingerprint
+	time.Sleep(50 * time.Millisecond)
+
+	// A new overlay is picked up
+	writeFile(t, dir, "config.test.yaml", "level: debug\n")
+	select {
+	case cfg := <-changed:
+		assert.Equal(t, limits{Rate: 5, Level: "debug"}, cfg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	// An invalid change is reported and ignored
+	writeFile(t, dir, filepath.Base(path), "rate: -10\n")
+	select {
+	case err := <-failed:
+		assert.ErrorIs(t, err, config.ErrInvalid)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the reload error")
+	}
+	assert.Equal(t, limits{Rate: 5, Level: "debug"}, store.Get())
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks the invariants of cfg, a configuration struct or a pointer to it, and returns
+// every violation at once as *FieldError values wrapping ErrInvalid, joined with errors.Join.
+//
+// The rules are declared with the validate tag, separated by commas:
+//
+//	min=N, max=N    bounds of a number or a duration, or of the length of a string, slice or map
+//	oneof=a b c     allowed values, separated by spaces
+//	url             an absolute URL, with a scheme and a host
+//	hostport        a "host:port" address, e.g. ":8080"
+//	nonzero         a value different from the zero value of its type
+//	excludes=Field  the field and its sibling Field cannot be both set
+//
+// The zero values are only checked by nonzero, so that optional fields can be left unset.
+// Then the Validate method of cfg and of its nested structs, if any, is called, see Validator;
+// for a struct passed by value, only a Validate method with a value receiver can be called.
+func Validate(cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return fmt.Errorf("failed to validate configuration: nil %T", cfg)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("failed to validate configuration: %T is not a struct", cfg)
+	}
+
+	var errs []error
+	validateStruct(v, "", &errs)
+	return errors.Join(errs...)
+}
+
+// validateStruct checks the fields of a struct and then calls its Validate method.
+func validateStruct(v reflect.Value, path string, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fieldPath := sf.Name
+		if path != "" {
+			fieldPath = path + "." + sf.Name
+		}
+		fv := v.Field(i)
+
+		if rules := sf.Tag.Get("validate"); rules != "" {
+			for _, rule := range strings.Split(rules, ",") {
+				if err := checkRule(v, fv, strings.TrimSpace(rule)); err != nil {
+					*errs = append(*errs, &FieldError{Field: fieldPath, Key: sf.Tag.Get("env"), Err: fmt.Errorf("%w: %v", ErrInvalid, err)})
+				}
+			}
+		}
+
+		if isNested(sf.Type) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			validateStruct(fv, fieldPath, errs)
+		}
+	}
+
+	// A struct passed by value is not addressable: only its value receiver methods are available
+	target := v
+	if v.CanAddr() {
+		target = v.Addr()
+	}
+	if validator, ok := target.Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			field := path
+			if field == "" {
+				field = t.Name()
+			}
+			*errs = append(*errs, &FieldError{Field: field, Err: fmt.Errorf("%w: %v", ErrInvalid, err)})
+		}
+	}
+}
+
+// checkRule checks a single rule against the field fv of the struct parent.
+func checkRule(parent, fv reflect.Value, rule string) error {
+	name, arg, _ := strings.Cut(rule, "=")
+
+	if name == "nonzero" {
+		if fv.IsZero() {
+			return errors.New("must be set")
+		}
+		return nil
+	}
+
+	if name == "excludes" {
+		other := parent.FieldByName(arg)
+		if !other.IsValid() {
+			return fmt.Errorf("unknown field %s in rule %q", arg, rule)
+		}
+		if !fv.IsZero() && !other.IsZero() {
+			return fmt.Errorf("cannot be set along with %s", arg)
+		}
+		return nil
+	}
+
+	if fv.IsZero() {
+		return nil
+	}
+	if fv.Kind() == reflect.Pointer {
+		fv = fv.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		return checkBound(fv, name, arg)
+	case "oneof":
+		value := fmt.Sprint(fv.Interface())
+		if allowed := strings.Fields(arg); !slices.Contains(allowed, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+		}
+	case "url":
+		u, err := url.Parse(fmt.Sprint(fv.Interface()))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+	case "hostport":
+		if _, _, err := net.SplitHostPort(fmt.Sprint(fv.Interface())); err != nil {
+			return errors.New("must be a host:port address")
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+	return nil
+}
+
+// checkBound checks a min or max rule: the value of a number or a duration,
+// the length of a string, a slice or a map.
+func checkBound(fv reflect.Value, name, arg string) error {
+	var value, bound float64
+	var err error
+
+	switch {
+	case fv.Type() == durationType:
+		var d time.Duration
+		d, err = time.ParseDuration(arg)
+		value, bound = float64(fv.Int()), float64(d)
+	case fv.CanInt():
+		value = float64(fv.Int())
+		bound, err = strconv.ParseFloat(arg, 64)
+	case fv.CanUint():
+		value = float64(fv.Uint())
+		bound, err = strconv.ParseFloat(arg, 64)
+	case fv.CanFloat():
+		value = fv.Float()
+		bound, err = strconv.ParseFloat(arg, 64)
+	case fv.Kind() == reflect.String, fv.Kind() == reflect.Slice, fv.Kind() == reflect.Map:
+		value = float64(fv.Len())
+		bound, err = strconv.ParseFloat(arg, 64)
+	default:
+		return fmt.Errorf("rule %s does not apply to %s", name, fv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid rule %s=%s", name, arg)
+	}
+
+	switch {
+	case name == "min" && value < bound:
+		return fmt.Errorf("must be at least %s", arg)
+	case name == "max" && value > bound:
+		return fmt.Errorf("must be at most %s", arg)
+	}
+	return nil
+}
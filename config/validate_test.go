@@ -0,0 +1,166 @@
+package config_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tlsConfig struct {
+	CertFile string `validate:"excludes=Insecure"`
+	Insecure bool
+}
+
+type serverConfig struct {
+	Port     int           `env:"PORT" validate:"min=1,max=65535"`
+	Addr     string        `validate:"hostport"`
+	Endpoint string        `validate:"url"`
+	Level    string        `validate:"oneof=debug info warn error"`
+	Name     string        `validate:"nonzero,max=8"`
+	Hosts    []string      `validate:"min=1"`
+	Timeout  time.Duration `validate:"max=1m"`
+	Ratio    float64       `validate:"max=1"`
+	TLS      tlsConfig
+}
+
+func (c *serverConfig) Validate() error {
+	if c.Level == "debug" && c.Port == 80 {
+		return errors.New("debug is not allowed on port 80")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg := serverConfig{
+			Port:     8080,
+			Addr:     ":8080",
+			Endpoint: "https://api.example.com/v1",
+			Level:    "info",
+			Name:     "api",
+			Hosts:    []string{"a"},
+			Timeout:  30 * time.Second,
+			Ratio:    0.5,
+			TLS:      tlsConfig{CertFile: "cert.pem"},
+		}
+		assert.NoError(t, config.Validate(&cfg))
+	})
+
+	t.Run("zero values are only checked by nonzero", func(t *testing.T) {
+		err := config.Validate(&serverConfig{})
+		require.Error(t, err)
+		assert.Equal(t, "Name: invalid configuration: must be set", err.Error())
+	})
+
+	t.Run("every violation is reported", func(t *testing.T) {
+		cfg := serverConfig{
+			Port:     80,
+			Addr:     "localhost",
+			Endpoint: "/v1",
+			Level:    "debug",
+			Name:     "a-very-long-name",
+			Hosts:    []string{},
+			Timeout:  time.Hour,
+			Ratio:    1.5,
+			TLS:      tlsConfig{CertFile: "cert.pem", Insecure: true},
+		}
+		cfg.Port = 70000
+
+		err := config.Validate(&cfg)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, config.ErrInvalid)
+
+		var fields []string
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var fieldErr *config.FieldError
+			require.ErrorAs(t, e, &fieldErr)
+			fields = append(fields, fieldErr.Field)
+		}
+		assert.Equal(t, []string{"Port", "Addr", "Endpoint", "Name", "Hosts", "Timeout", "Ratio", "TLS.CertFile"}, fields)
+		assert.Contains(t, err.Error(), "Port (PORT): invalid configuration: must be at most 65535")
+		assert.Contains(t, err.Error(), "TLS.CertFile: invalid configuration: cannot be set along with Insecure")
+	})
+
+	t.Run("validate method", func(t *testing.T) {
+		cfg := serverConfig{Port: 80, Level: "debug", Name: "api"}
+		err := config.Validate(&cfg)
+		assert.ErrorContains(t, err, "serverConfig: invalid configuration: debug is not allowed on port 80")
+	})
+
+	t.Run("validate method of a struct value", func(t *testing.T) {
+		err := config.Validate(limits{Rate: 0})
+		assert.ErrorIs(t, err, config.ErrInvalid)
+		assert.ErrorContains(t, err, "limits: invalid configuration: rate must be positive")
+
+		assert.NoError(t, config.Validate(limits{Rate: 1}))
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		var cfg struct {
+			A string `validate:"email"`
+			B bool   `validate:"min=1"`
+			C string `validate:"excludes=D"`
+		}
+		cfg.A, cfg.B, cfg.C = "a", true, "c"
+		err := config.Validate(&cfg)
+		assert.ErrorContains(t, err, `unknown rule "email"`)
+		assert.ErrorContains(t, err, "rule min does not apply to bool")
+		assert.ErrorContains(t, err, "unknown field D")
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Error(t, config.Validate(42))
+		assert.Error(t, config.Validate((*serverConfig)(nil)))
+	})
+}
+
+func TestReport(t *testing.T) {
+	type dbConfig struct {
+		URL      string        `env:"DB_URL"`
+		Password config.Secret `env:"DB_PASSWORD"`
+		Token    string        `redact:"true"`
+	}
+	type appConfig struct {
+		Port  int
+		Hosts []string
+		DB    *dbConfig
+		Empty config.Secret
+	}
+
+	cfg := appConfig{
+		Port:  8080,
+		Hosts: []string{"a", "b"},
+		DB:    &dbConfig{URL: "postgres://db", Password: config.NewSecret("hunter2"), Token: "t0ken"},
+	}
+	report := config.NewReport(&cfg, map[string]string{"Port": "default", "DB.Password": "env:DB_PASSWORD_FILE"})
+
+	assert.Equal(t, config.Report{
+		{Field: "Port", Value: "8080", Source: "default"},
+		{Field: "Hosts", Value: "[a b]"},
+		{Field: "DB.URL", Value: "postgres://db"},
+		{Field: "DB.Password", Value: config.Redacted, Source: "env:DB_PASSWORD_FILE"},
+		{Field: "DB.Token", Value: config.Redacted},
+		{Field: "Empty", Value: ""},
+	}, report)
+
+	assert.Equal(t, ""+
+		"Port        = 8080 (default)\n"+
+		"Hosts       = [a b]\n"+
+		"DB.URL      = postgres://db\n"+
+		"DB.Password = [REDACTED] (env:DB_PASSWORD_FILE)\n"+
+		"DB.Token    = [REDACTED]\n"+
+		"Empty       = \n", report.String())
+
+	out, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "hunter2")
+	assert.NotContains(t, string(out), "t0ken")
+	assert.Contains(t, string(out), `{"field":"Port","value":"8080","source":"default"}`)
+
+	assert.Nil(t, config.NewReport(42, nil))
+}
@@ -0,0 +1,66 @@
+// Package identity provides the principal on whose behalf a request is handled, carried by the context.
+package identity
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal is an authenticated identity, e.g. the user of an HTTP request
+// or the account running a background job.
+type Principal struct {
+	// Subject is the unique identifier of the principal, e.g. a user id or a service account name.
+	Subject string
+	// Tenant is the tenant the principal belongs to, if any.
+	Tenant string
+	// Roles are the roles granted to the principal.
+	Roles []string
+	// AuthMethod is the way the principal was authenticated, e.g. "jwt", "api-key" or AuthMethodProcess.
+	AuthMethod string
+}
+
+// AuthMethodProcess is the authentication method of the principal running the process,
+// the fallback for the work done outside of a request.
+const AuthMethodProcess = "process"
+
+// IsZero reports whether p is the zero Principal.
+func (p Principal) IsZero() bool {
+	return p.Subject == "" && p.Tenant == "" && len(p.Roles) == 0 && p.AuthMethod == ""
+}
+
+// HasRole reports whether role is granted to the principal.
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Fields returns the non-empty attributes of the principal, to be attached to log entries.
+func (p Principal) Fields() map[string]any {
+	fields := make(map[string]any)
+	if p.Subject != "" {
+		fields["principal"] = p.Subject
+	}
+	if p.Tenant != "" {
+		fields["tenant"] = p.Tenant
+	}
+	if len(p.Roles) > 0 {
+		fields["roles"] = p.Roles
+	}
+	if p.AuthMethod != "" {
+		fields["auth_method"] = p.AuthMethod
+	}
+	return fields
+}
+
+// principalKey is the context key of the principal.
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal carried by ctx, if any.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,41 @@
+package identity_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deadelus/go-clean-app/v2/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal_Context(t *testing.T) {
+	_, ok := identity.PrincipalFrom(context.Background())
+	assert.False(t, ok)
+
+	p := identity.Principal{Subject: "alice", Tenant: "acme", Roles: []string{"admin"}, AuthMethod: "jwt"}
+	ctx := identity.WithPrincipal(context.Background(), p)
+
+	got, ok := identity.PrincipalFrom(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, p, got)
+
+	// A nested principal replaces the outer one
+	got, _ = identity.PrincipalFrom(identity.WithPrincipal(ctx, identity.Principal{Subject: "bob"}))
+	assert.Equal(t, "bob", got.Subject)
+}
+
+func TestPrincipal_Methods(t *testing.T) {
+	var zero identity.Principal
+	assert.True(t, zero.IsZero())
+	assert.Empty(t, zero.Fields())
+
+	p := identity.Principal{Subject: "alice", Roles: []string{"reader", "admin"}, AuthMethod: "jwt"}
+	assert.False(t, p.IsZero())
+	assert.True(t, p.HasRole("admin"))
+	assert.False(t, p.HasRole("owner"))
+	assert.Equal(t, map[string]any{
+		"principal":   "alice",
+		"roles":       []string{"reader", "admin"},
+		"auth_method": "jwt",
+	}, p.Fields())
+}
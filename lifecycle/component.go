@@ -0,0 +1,155 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Component is a long-running service, such as an HTTP server or a queue consumer.
+type Component interface {
+	// Name returns the unique name of the component.
+	Name() string
+	// Start runs the component and blocks until it stops, like http.Server.ListenAndServe.
+	// The context is canceled once the component is stopped.
+	Start(ctx context.Context) error
+	// Stop asks the component to stop and makes Start return.
+	// The context carries the shutdown deadline.
+	Stop(ctx context.Context) error
+}
+
+// State is the state of a managed component or of the lifecycle itself.
+type State int32
+
+// States of a managed component or of the lifecycle.
+const (
+	StateCreated State = iota
+	StateStarting
+	StateRunning
+	StateStopping
+	StateStopped
+	StateFailed
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("State(%d)", int32(s))
+	}
+}
+
+// Managed runs a Component in its own goroutine and tracks its state.
+// Its Start and Stop methods are meant to be registered as startup and shutdown hooks.
+type Managed struct {
+	component Component
+	onExit    func(err error)
+	state     atomic.Int32
+	mu        sync.Mutex
+	err       error
+	cancel    context.CancelFunc
+	exited    chan struct{}
+}
+
+// Manage wraps a component. onExit, if not nil, is called when the component
+// exits with an error while running, that is without being stopped.
+func Manage(c Component, onExit func(err error)) *Managed {
+	return &Managed{
+		component: c,
+		onExit:    onExit,
+		exited:    make(chan struct{}),
+	}
+}
+
+// Name returns the name of the component.
+func (m *Managed) Name() string {
+	return m.component.Name()
+}
+
+// State returns the current state of the component.
+func (m *Managed) State() State {
+	return State(m.state.Load())
+}
+
+// Err returns the error the component exited with, if any.
+func (m *Managed) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.err
+}
+
+// Start launches the component in its own goroutine and returns without waiting for it.
+// The component is not bound to ctx, which only covers the startup phase.
+func (m *Managed) Start(ctx context.Context) error {
+	if !m.state.CompareAndSwap(int32(StateCreated), int32(StateStarting)) {
+		return fmt.Errorf("failed to start %s: component is %s", m.Name(), m.State())
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	m.cancel = cancel
+
+	m.state.Store(int32(StateRunning))
+	go m.run(runCtx)
+
+	return nil
+}
+
+// run executes the component until it exits and records the outcome.
+func (m *Managed) run(ctx context.Context) {
+	defer close(m.exited)
+
+	err := m.component.Start(ctx)
+
+	m.mu.Lock()
+	m.err = err
+	m.mu.Unlock()
+
+	// A component exiting on its own, without being stopped, is a failure if it returns an error.
+	if m.state.CompareAndSwap(int32(StateRunning), int32(StateStopped)) && err != nil {
+		m.state.Store(int32(StateFailed))
+		if m.onExit != nil {
+			m.onExit(fmt.Errorf("%s exited: %w", m.Name(), err))
+		}
+	}
+}
+
+// Stop stops the component and waits for it to exit, or for ctx to be done.
+// It does nothing if the component is not running, but returns the error of a component
+// that exited with an error while running, so that the shutdown report records the failure.
+func (m *Managed) Stop(ctx context.Context) error {
+	if !m.state.CompareAndSwap(int32(StateRunning), int32(StateStopping)) {
+		if err := m.Err(); err != nil && m.State() == StateFailed {
+			return fmt.Errorf("%s exited: %w", m.Name(), err)
+		}
+		return nil
+	}
+	defer m.cancel()
+
+	if err := m.component.Stop(ctx); err != nil {
+		m.state.Store(int32(StateFailed))
+		return err
+	}
+
+	select {
+	case <-m.exited:
+		m.state.Store(int32(StateStopped))
+		return nil
+	case <-ctx.Done():
+		m.state.Store(int32(StateFailed))
+		return fmt.Errorf("%s did not exit: %w", m.Name(), ctx.Err())
+	}
+}
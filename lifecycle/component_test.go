@@ -0,0 +1,127 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeComponent runs until it is stopped or fails with the error sent on fail.
+type fakeComponent struct {
+	stop    chan struct{}
+	fail    chan error
+	stopErr error
+}
+
+func newFakeComponent() *fakeComponent {
+	return &fakeComponent{stop: make(chan struct{}), fail: make(chan error, 1)}
+}
+
+func (f *fakeComponent) Name() string { return "fake" }
+
+func (f *fakeComponent) Start(ctx context.Context) error {
+	select {
+	case <-f.stop:
+		return nil
+	case err := <-f.fail:
+		return err
+	}
+}
+
+func (f *fakeComponent) Stop(ctx context.Context) error {
+	if f.stopErr != nil {
+		return f.stopErr
+	}
+	close(f.stop)
+	return nil
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "created", lifecycle.StateCreated.String())
+	assert.Equal(t, "starting", lifecycle.StateStarting.String())
+	assert.Equal(t, "running", lifecycle.StateRunning.String())
+	assert.Equal(t, "stopping", lifecycle.StateStopping.String())
+	assert.Equal(t, "stopped", lifecycle.StateStopped.String())
+	assert.Equal(t, "failed", lifecycle.StateFailed.String())
+	assert.Equal(t, "State(42)", lifecycle.State(42).String())
+}
+
+func TestManaged(t *testing.T) {
+	t.Run("start and stop", func(t *testing.T) {
+		c := newFakeComponent()
+		m := lifecycle.Manage(c, func(error) { t.Error("unexpected exit") })
+		assert.Equal(t, "fake", m.Name())
+		assert.Equal(t, lifecycle.StateCreated, m.State())
+
+		require.NoError(t, m.Start(context.Background()))
+		assert.Equal(t, lifecycle.StateRunning, m.State())
+		assert.Error(t, m.Start(context.Background()))
+
+		require.NoError(t, m.Stop(context.Background()))
+		assert.Equal(t, lifecycle.StateStopped, m.State())
+		assert.NoError(t, m.Err())
+	})
+
+	t.Run("exit with an error while running", func(t *testing.T) {
+		c := newFakeComponent()
+		exited := make(chan error, 1)
+		m := lifecycle.Manage(c, func(err error) { exited <- err })
+
+		require.NoError(t, m.Start(context.Background()))
+		errMock := errors.New("mock error")
+		c.fail <- errMock
+
+		select {
+		case err := <-exited:
+			assert.ErrorIs(t, err, errMock)
+			assert.Contains(t, err.Error(), "fake exited")
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the component to exit")
+		}
+		assert.Equal(t, lifecycle.StateFailed, m.State())
+		assert.ErrorIs(t, m.Err(), errMock)
+		assert.ErrorIs(t, m.Stop(context.Background()), errMock, "the exit error is reported at shutdown")
+	})
+
+	t.Run("stop error", func(t *testing.T) {
+		c := newFakeComponent()
+		c.stopErr = errors.New("mock error")
+		m := lifecycle.Manage(c, nil)
+
+		require.NoError(t, m.Start(context.Background()))
+		assert.ErrorIs(t, m.Stop(context.Background()), c.stopErr)
+		assert.Equal(t, lifecycle.StateFailed, m.State())
+	})
+
+	t.Run("stop without start", func(t *testing.T) {
+		m := lifecycle.Manage(newFakeComponent(), nil)
+
+		assert.NoError(t, m.Stop(context.Background()))
+		assert.Equal(t, lifecycle.StateCreated, m.State())
+	})
+
+	t.Run("component not exiting", func(t *testing.T) {
+		c := newFakeComponent()
+		m := lifecycle.Manage(stuckComponent{c}, nil)
+
+		require.NoError(t, m.Start(context.Background()))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, m.Stop(ctx), context.DeadlineExceeded)
+		assert.Equal(t, lifecycle.StateFailed, m.State())
+		c.fail <- nil
+	})
+}
+
+// stuckComponent ignores Stop.
+type stuckComponent struct {
+	*fakeComponent
+}
+
+func (s stuckComponent) Stop(ctx context.Context) error { return nil }
@@ -0,0 +1,279 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Priorities commonly used with the Priority hook option.
+const (
+	// PriorityFirst shuts a hook down before the hooks with the default priority.
+	PriorityFirst = 100
+	// PriorityDefault is the priority of the hooks registered without the Priority option.
+	PriorityDefault = 0
+	// PriorityLast shuts a hook down after the hooks with the default priority, e.g. a logger flush.
+	PriorityLast = -100
+)
+
+// ErrDependencyCycle is returned by Register when the dependencies of a hook form a cycle.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// PanicError is the error of a hook that panicked.
+type PanicError struct {
+	// Value is the value the hook panicked with.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error returns the panic value followed by the stack trace.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// HookOption configures a hook registered with Register.
+type HookOption func(*hook)
+
+// Priority sets the priority of a hook. Among the hooks whose dependents are stopped,
+// the hooks with the highest priority are shut down first. The default is PriorityDefault.
+// Startup hooks follow the reverse order: the hooks with the lowest priority are started first.
+func Priority(priority int) HookOption {
+	return func(h *hook) {
+		h.priority = priority
+	}
+}
+
+// DependsOn declares the hooks used by a hook. A hook is shut down before the hooks it depends on,
+// e.g. an HTTP server is drained before the database pool it uses is closed.
+// Startup hooks follow the reverse order: a hook is started after the hooks it depends on.
+// The dependencies do not need to be registered yet; unknown names are ignored at shutdown.
+func DependsOn(names ...string) HookOption {
+	return func(h *hook) {
+		h.dependsOn = append(h.dependsOn, names...)
+	}
+}
+
+// Timeout sets the maximum duration of a hook. Once it is exceeded, the context given
+// to the hook is canceled and the hook is abandoned: the startup or shutdown goes on without waiting for it.
+func Timeout(timeout time.Duration) HookOption {
+	return func(h *hook) {
+		h.timeout = timeout
+	}
+}
+
+// hook is a function registered to be executed during startup or graceful shutdown.
+type hook struct {
+	name      string
+	fn        func(ctx context.Context) error
+	priority  int
+	dependsOn []string
+	timeout   time.Duration
+}
+
+// newHook creates a hook and applies its options.
+func newHook(name string, fn func(ctx context.Context) error, opts []HookOption) *hook {
+	h := &hook{name: name, fn: fn}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// run executes the hook and returns its error.
+// A panic is recovered and returned as a *PanicError.
+// The hook is abandoned if it exceeds its timeout or the deadline of ctx;
+// timedOut is true when it is abandoned or returns the error of the context.
+func (h *hook) run(ctx context.Context) (timedOut bool, err error) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+
+		result <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return ctx.Err() != nil && errors.Is(err, ctx.Err()), err
+	case <-ctx.Done():
+		select {
+		case err := <-result:
+			return errors.Is(err, ctx.Err()), err
+		default:
+			return true, fmt.Errorf("abandoned: %w", ctx.Err())
+		}
+	}
+}
+
+// hookSet is a set of named hooks ordered by dependencies and priorities.
+type hookSet struct {
+	hooks map[string]*hook
+	order []string
+}
+
+// newHookSet creates an empty hookSet.
+func newHookSet() *hookSet {
+	return &hookSet{hooks: make(map[string]*hook)}
+}
+
+// add adds a hook to the set.
+// It returns an error wrapping ErrAlreadyRegistered if a hook with the same name is in the set,
+// or ErrDependencyCycle if the dependencies of the hook form a cycle.
+func (s *hookSet) add(h *hook) error {
+	if _, exists := s.hooks[h.name]; exists {
+		return fmt.Errorf("failed to register %s: %w", h.name, ErrAlreadyRegistered)
+	}
+
+	if cycle := s.findCycle(h); cycle != nil {
+		return fmt.Errorf("failed to register %s: %w: %s", h.name, ErrDependencyCycle, strings.Join(cycle, " -> "))
+	}
+
+	s.hooks[h.name] = h
+	s.order = append(s.order, h.name)
+	return nil
+}
+
+// remove removes the hook with this name from the set and reports whether it was there.
+func (s *hookSet) remove(name string) bool {
+	if _, exists := s.hooks[name]; !exists {
+		return false
+	}
+
+	delete(s.hooks, name)
+	s.order = slices.DeleteFunc(s.order, func(n string) bool { return n == name })
+	return true
+}
+
+// has reports whether a hook with this name is in the set.
+func (s *hookSet) has(name string) bool {
+	_, exists := s.hooks[name]
+	return exists
+}
+
+// filter returns a set holding the hooks for which keep returns true.
+func (s *hookSet) filter(keep func(h *hook) bool) *hookSet {
+	filtered := newHookSet()
+	for _, name := range s.order {
+		if h := s.hooks[name]; keep(h) {
+			filtered.hooks[name] = h
+			filtered.order = append(filtered.order, name)
+		}
+	}
+	return filtered
+}
+
+// findCycle returns the dependency path leading from h back to itself, or nil if there is none.
+func (s *hookSet) findCycle(h *hook) []string {
+	visited := make(map[string]bool)
+
+	var walk func(name string, path []string) []string
+	walk = func(name string, path []string) []string {
+		if name == h.name {
+			return append(path, name)
+		}
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+
+		dep, exists := s.hooks[name]
+		if !exists {
+			return nil
+		}
+		for _, next := range dep.dependsOn {
+			if cycle := walk(next, append(path, name)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+
+	for _, next := range h.dependsOn {
+		if cycle := walk(next, []string{h.name}); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// stages groups the hooks in shutdown stages.
+// A hook is ready once every hook depending on it is stopped,
+// and each stage holds the ready hooks with the highest priority, in registration order.
+func (s *hookSet) stages() [][]*hook {
+	dependents := make(map[string]int)
+	for _, h := range s.hooks {
+		for _, dep := range h.dependsOn {
+			if _, exists := s.hooks[dep]; exists {
+				dependents[dep]++
+			}
+		}
+	}
+
+	remaining := make([]*hook, 0, len(s.order))
+	for _, name := range s.order {
+		remaining = append(remaining, s.hooks[name])
+	}
+
+	var stages [][]*hook
+	for len(remaining) > 0 {
+		best := math.MinInt
+		for _, h := range remaining {
+			if dependents[h.name] == 0 && h.priority > best {
+				best = h.priority
+			}
+		}
+
+		var stage, rest []*hook
+		for _, h := range remaining {
+			if dependents[h.name] == 0 && h.priority == best {
+				stage = append(stage, h)
+			} else {
+				rest = append(rest, h)
+			}
+		}
+
+		// Cycles are rejected by add, this only guards against an endless loop.
+		if len(stage) == 0 {
+			stage, rest = rest, nil
+		}
+
+		for _, h := range stage {
+			for _, dep := range h.dependsOn {
+				dependents[dep]--
+			}
+		}
+
+		stages = append(stages, stage)
+		remaining = rest
+	}
+
+	return stages
+}
+
+// stageNames returns the names of the hooks of each stage.
+func stageNames(stages [][]*hook) [][]string {
+	var names [][]string
+	for _, stage := range stages {
+		stageNames := make([]string, 0, len(stage))
+		for _, h := range stage {
+			stageNames = append(stageNames, h.name)
+		}
+		names = append(names, stageNames)
+	}
+	return names
+}
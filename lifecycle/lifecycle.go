@@ -3,32 +3,82 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
+	"time"
 )
 
+// ErrAlreadyRegistered is returned when registering a hook under a name already in use.
+var ErrAlreadyRegistered = errors.New("hook already registered")
+
+// ErrShuttingDown is returned when registering a hook once the shutdown has begun.
+var ErrShuttingDown = errors.New("shutdown in progress")
+
+// FlushGracePeriod is the time given to the hooks registered with PriorityLast, such as the logger flushes,
+// once the shutdown deadline is exceeded, so that the logs of a timed out shutdown are not lost.
+var FlushGracePeriod = time.Second
+
 // Lifecycle interface defines methods for managing application lifecycle events.
 type Lifecycle interface {
 	Done() <-chan struct{}
-	Register(name string, gracefull func() error) error
+	ShuttingDown() <-chan struct{}
+	State() State
+	Register(name string, gracefull func() error, opts ...HookOption) error
+	RegisterContext(name string, gracefull func(ctx context.Context) error, opts ...HookOption) error
+	Unregister(name string) bool
+	OnStart(name string, start func(ctx context.Context) error, opts ...HookOption) error
+	Start(ctx context.Context) error
+	Wait() (Report, error)
 }
 
-// Gracefull represents a list of functions to be executed during graceful shutdown.
+// Gracefull represents a list of functions to be executed during startup and graceful shutdown.
+// The functions are executed in stages: the hooks of a stage run concurrently
+// and a stage starts once the previous one is over.
+// It is safe for concurrent use.
 type Gracefull struct {
-	functions map[string]func() error
-	done      chan struct{}
+	mu           sync.Mutex
+	shutdown     *hookSet
+	startup      *hookSet
+	timeout      time.Duration
+	started      map[string]bool
+	state        State
+	report       Report
+	shuttingDown chan struct{}
+	done         chan struct{}
 }
 
 // Done returns a channel that is closed when the graceful shutdown is complete.
+// Any number of goroutines can wait on it.
 func (g *Gracefull) Done() <-chan struct{} {
 	return g.done
 }
 
+// ShuttingDown returns a channel that is closed when the graceful shutdown begins,
+// before any shutdown hook is executed.
+func (g *Gracefull) ShuttingDown() <-chan struct{} {
+	return g.shuttingDown
+}
+
+// State returns the current state of the lifecycle: StateCreated until Start is called,
+// StateStarting and then StateRunning, or StateFailed if a startup hook failed,
+// and finally StateStopping during the graceful shutdown and StateStopped once it is complete.
+func (g *Gracefull) State() State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.state
+}
+
 // NewGracefullShutdown is the constructor of the shutdown ochestrator.
 func NewGracefullShutdown(ctx context.Context) *Gracefull {
 	life := &Gracefull{
-		functions: make(map[string]func() error),
-		done:      make(chan struct{}),
+		shutdown:     newHookSet(),
+		startup:      newHookSet(),
+		state:        StateCreated,
+		shuttingDown: make(chan struct{}),
+		done:         make(chan struct{}),
 	}
 
 	go func() {
@@ -39,41 +89,184 @@ func NewGracefullShutdown(ctx context.Context) *Gracefull {
 	return life
 }
 
+// SetTimeout sets the deadline of the whole shutdown, counted from its beginning.
+// The hooks still running at the deadline are abandoned and the stages left are skipped,
+// except for the hooks registered with PriorityLast, given FlushGracePeriod to complete.
+// A zero timeout, the default, waits for the hooks indefinitely.
+func (g *Gracefull) SetTimeout(timeout time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.timeout = timeout
+}
+
+// TimedOut returns the names of the hooks abandoned because they exceeded their timeout
+// or the shutdown deadline. It is empty until the shutdown is over.
+func (g *Gracefull) TimedOut() []string {
+	select {
+	case <-g.done:
+		return g.report.TimedOut()
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the graceful shutdown is complete and returns its report,
+// along with the errors of the hooks joined, so that an unclean shutdown can be detected.
+// It can be called any number of times, from any number of goroutines.
+func (g *Gracefull) Wait() (Report, error) {
+	<-g.done
+	return g.report, g.report.Err()
+}
+
 // Register adds a function to the list of functions to be executed during graceful shutdown.
-func (g *Gracefull) Register(name string, gracefull func() error) error {
-	if _, exists := g.functions[name]; exists {
-		return nil // Already registered
+// It returns an error wrapping ErrAlreadyRegistered if the name is already in use,
+// ErrDependencyCycle if the dependencies of the hook form a cycle,
+// or ErrShuttingDown if the shutdown has begun.
+func (g *Gracefull) Register(name string, gracefull func() error, opts ...HookOption) error {
+	return g.RegisterContext(name, func(context.Context) error { return gracefull() }, opts...)
+}
+
+// RegisterContext is like Register for functions accepting a context.
+// The context carries the shutdown deadline and the hook timeout, if any.
+func (g *Gracefull) RegisterContext(name string, gracefull func(ctx context.Context) error, opts ...HookOption) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.isShuttingDown() {
+		return fmt.Errorf("failed to register %s: %w", name, ErrShuttingDown)
+	}
+
+	return g.shutdown.add(newHook(name, gracefull, opts))
+}
+
+// Unregister removes the shutdown hook registered under name,
+// e.g. for a resource closing itself before the application shuts down.
+// It reports whether the hook was removed: false if there is no such hook
+// or if the shutdown has begun.
+func (g *Gracefull) Unregister(name string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.isShuttingDown() {
+		return false
 	}
-	g.functions[name] = gracefull
-	return nil
+
+	return g.shutdown.remove(name)
 }
 
-// gracefullAll executes all registered functions in the order they were added.
+// Stages returns the names of the registered shutdown hooks grouped by stage, in execution order.
+func (g *Gracefull) Stages() [][]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return stageNames(g.shutdown.stages())
+}
+
+// gracefullAll executes all registered functions stage by stage.
+// The functions sharing their name with a startup hook are skipped unless this hook was started.
+// Once the deadline is exceeded, only the functions registered with PriorityLast are executed.
 func (g *Gracefull) gracefullAll() {
 	log.Println("Shutting down in progress...")
+	start := time.Now()
+
+	g.mu.Lock()
+	g.state = StateStopping
+	close(g.shuttingDown)
+	timeout := g.timeout
+	hooks := g.shutdown.filter(func(h *hook) bool {
+		if g.startup.has(h.name) && !g.started[h.name] {
+			log.Printf("Gracefull shutdown of %s skipped: not started", h.name)
+			return false
+		}
+		return true
+	})
+	g.mu.Unlock()
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	// graceCtx bounds the hooks with PriorityLast run after the deadline
+	var graceCtx context.Context
+
+	var results []HookResult
+	for i, stage := range hooks.stages() {
+		stageResults := make([]HookResult, len(stage))
+
+		stageCtx := ctx
+		if ctx.Err() != nil {
+			if graceCtx == nil {
+				var cancelGrace context.CancelFunc
+				graceCtx, cancelGrace = context.WithTimeout(context.Background(), FlushGracePeriod)
+				defer cancelGrace()
+			}
+			stageCtx = graceCtx
+		}
+
+		wg := &sync.WaitGroup{}
+		for j, h := range stage {
+			stageResults[j] = HookResult{Name: h.name, Stage: i}
+			if ctx.Err() != nil && h.priority > PriorityLast {
+				log.Printf("Gracefull shutdown of %s skipped: shutdown deadline exceeded", h.name)
+				stageResults[j].Err = fmt.Errorf("skipped: %w", ctx.Err())
+				stageResults[j].TimedOut = true
+				continue
+			}
 
-	wg := &sync.WaitGroup{}
-	for name, gracefullFunc := range g.functions {
-		wg.Add(1)
-		k, v := name, gracefullFunc
-		go g.gracefullOne(wg, k, v)
+			wg.Add(1)
+			go g.gracefullOne(stageCtx, wg, h, &stageResults[j])
+		}
+		wg.Wait()
+		results = append(results, stageResults...)
 	}
-	wg.Wait()
+
+	g.mu.Lock()
+	g.report = Report{Hooks: results, Duration: time.Since(start)}
+	g.state = StateStopped
+	g.mu.Unlock()
 
 	log.Println("Shutdown is over.")
 
-	g.done <- struct{}{}
+	close(g.done)
 }
 
-// gracefullOne executes a single registered function and logs any errors.
-func (g *Gracefull) gracefullOne(wg *sync.WaitGroup, name string, gracefullFunc func() error) {
+// isShuttingDown reports whether the graceful shutdown has begun.
+func (g *Gracefull) isShuttingDown() bool {
+	select {
+	case <-g.shuttingDown:
+		return true
+	default:
+		return false
+	}
+}
+
+// gracefullOne executes a single registered function, logs any errors and records its result.
+// The function is abandoned if it exceeds its timeout or the shutdown deadline.
+func (g *Gracefull) gracefullOne(ctx context.Context, wg *sync.WaitGroup, h *hook, res *HookResult) {
 	defer wg.Done()
 
-	if err := gracefullFunc(); err != nil {
-		log.Printf("Error during gracefull shutdown of %s: %v", name, err)
+	start := time.Now()
+	timedOut, err := h.run(ctx)
+	res.Duration = time.Since(start)
+	res.Err = err
+	res.TimedOut = timedOut
 
-		return
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		res.Panic = panicErr.Value
 	}
 
-	log.Printf("Gracefull shutdown of %s completed successfully", name)
+	switch {
+	case res.Panic != nil:
+		log.Printf("Panic during gracefull shutdown of %s: %v", h.name, err)
+	case timedOut:
+		log.Printf("Gracefull shutdown of %s %v", h.name, err)
+	case err != nil:
+		log.Printf("Error during gracefull shutdown of %s: %v", h.name, err)
+	default:
+		log.Printf("Gracefull shutdown of %s completed successfully", h.name)
+	}
 }
@@ -3,10 +3,14 @@ package lifecycle_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/deadelus/go-clean-app/v2/lifecycle"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGracefull_Register(t *testing.T) {
@@ -20,7 +24,79 @@ func TestGracefull_Register(t *testing.T) {
 
 	// Test already registered
 	err = g.Register("test1", fn1)
+	assert.ErrorIs(t, err, lifecycle.ErrAlreadyRegistered)
+}
+
+func TestGracefull_Unregister(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := lifecycle.NewGracefullShutdown(ctx)
+
+	called := false
+	require.NoError(t, g.Register("closed-early", func() error {
+		called = true
+		return nil
+	}))
+	require.NoError(t, g.Register("other", func() error { return nil }))
+
+	assert.True(t, g.Unregister("closed-early"))
+	assert.False(t, g.Unregister("closed-early"))
+	assert.False(t, g.Unregister("missing"))
+	assert.Equal(t, [][]string{{"other"}}, g.Stages())
+
+	// The name can be reused once unregistered
+	require.NoError(t, g.Register("closed-early", func() error { return nil }))
+
+	cancel()
+	<-g.Done()
+	assert.False(t, called)
+	assert.False(t, g.Unregister("other"))
+}
+
+func TestGracefull_Register_AfterShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := lifecycle.NewGracefullShutdown(ctx)
+
+	cancel()
+	_, err := g.Wait()
+	require.NoError(t, err)
+
+	noop := func(context.Context) error { return nil }
+	assert.ErrorIs(t, g.Register("late", func() error { return nil }), lifecycle.ErrShuttingDown)
+	assert.ErrorIs(t, g.RegisterContext("late", noop), lifecycle.ErrShuttingDown)
+	assert.ErrorIs(t, g.OnStart("late", noop), lifecycle.ErrShuttingDown)
+	assert.ErrorIs(t, g.Start(context.Background()), lifecycle.ErrShuttingDown)
+	<-g.Done()
+}
+
+func TestGracefull_ConcurrentRegistration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g := lifecycle.NewGracefullShutdown(ctx)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("conn-%d", i)
+			err := g.Register(name, func() error { return nil })
+			if err != nil {
+				assert.ErrorIs(t, err, lifecycle.ErrShuttingDown)
+				return
+			}
+			if i%2 == 0 {
+				g.Unregister(name)
+			}
+		}()
+		if i == 25 {
+			cancel()
+		}
+	}
+	wg.Wait()
+
+	_, err := g.Wait()
 	assert.NoError(t, err)
+	<-g.Done()
 }
 
 func TestGracefull_Shutdown(t *testing.T) {
@@ -54,3 +130,520 @@ func TestGracefull_Shutdown_Error(t *testing.T) {
 
 	<-g.Done() // wait for shutdown to complete
 }
+
+func TestGracefull_Stages(t *testing.T) {
+	noop := func() error { return nil }
+
+	tests := []struct {
+		name     string
+		register func(g *lifecycle.Gracefull)
+		want     [][]string
+	}{
+		{
+			name: "no ordering runs in a single stage",
+			register: func(g *lifecycle.Gracefull) {
+				g.Register("a", noop)
+				g.Register("b", noop)
+			},
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "dependents are stopped first",
+			register: func(g *lifecycle.Gracefull) {
+				g.Register("database", noop)
+				g.Register("cache", noop)
+				g.Register("http", noop, lifecycle.DependsOn("database", "cache"))
+				g.Register("worker", noop, lifecycle.DependsOn("database"))
+			},
+			want: [][]string{{"http", "worker"}, {"database", "cache"}},
+		},
+		{
+			name: "dependency chain",
+			register: func(g *lifecycle.Gracefull) {
+				g.Register("c", noop, lifecycle.DependsOn("b"))
+				g.Register("b", noop, lifecycle.DependsOn("a"))
+				g.Register("a", noop)
+			},
+			want: [][]string{{"c"}, {"b"}, {"a"}},
+		},
+		{
+			name: "priorities",
+			register: func(g *lifecycle.Gracefull) {
+				g.Register("logger", noop, lifecycle.Priority(lifecycle.PriorityLast))
+				g.Register("service", noop)
+				g.Register("ingress", noop, lifecycle.Priority(lifecycle.PriorityFirst))
+			},
+			want: [][]string{{"ingress"}, {"service"}, {"logger"}},
+		},
+		{
+			name: "dependencies win over priorities",
+			register: func(g *lifecycle.Gracefull) {
+				g.Register("database", noop, lifecycle.Priority(lifecycle.PriorityFirst))
+				g.Register("http", noop, lifecycle.DependsOn("database"))
+				g.Register("logger", noop, lifecycle.Priority(lifecycle.PriorityLast))
+			},
+			want: [][]string{{"http"}, {"database"}, {"logger"}},
+		},
+		{
+			name: "unknown dependencies are ignored",
+			register: func(g *lifecycle.Gracefull) {
+				g.Register("http", noop, lifecycle.DependsOn("missing"))
+			},
+			want: [][]string{{"http"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			g := lifecycle.NewGracefullShutdown(ctx)
+
+			tt.register(g)
+
+			assert.Equal(t, tt.want, g.Stages())
+		})
+	}
+}
+
+func TestGracefull_Register_Cycle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g := lifecycle.NewGracefullShutdown(ctx)
+	noop := func() error { return nil }
+
+	assert.NoError(t, g.Register("a", noop, lifecycle.DependsOn("b")))
+	assert.NoError(t, g.Register("b", noop, lifecycle.DependsOn("c")))
+
+	err := g.Register("c", noop, lifecycle.DependsOn("a"))
+	assert.ErrorIs(t, err, lifecycle.ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "c -> a -> b -> c")
+
+	err = g.Register("self", noop, lifecycle.DependsOn("self"))
+	assert.ErrorIs(t, err, lifecycle.ErrDependencyCycle)
+
+	assert.Equal(t, [][]string{{"a"}, {"b"}}, g.Stages())
+}
+
+func TestGracefull_Shutdown_Order(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := lifecycle.NewGracefullShutdown(ctx)
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) func() error {
+		return func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	g.Register("logger", record("logger"), lifecycle.Priority(lifecycle.PriorityLast))
+	g.Register("database", record("database"))
+	g.Register("http", record("http"), lifecycle.DependsOn("database"))
+
+	cancel()
+
+	<-g.Done() // wait for shutdown to complete
+	assert.Equal(t, []string{"http", "database", "logger"}, calls)
+}
+
+func waitDone(t *testing.T, g *lifecycle.Gracefull) {
+	t.Helper()
+
+	select {
+	case <-g.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for shutdown to complete")
+	}
+}
+
+func TestGracefull_HookTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := lifecycle.NewGracefullShutdown(ctx)
+
+	hung := make(chan struct{})
+	defer close(hung)
+
+	hookErr := make(chan error, 1)
+	g.RegisterContext("hung", func(ctx context.Context) error {
+		<-hung
+		return nil
+	}, lifecycle.Timeout(20*time.Millisecond))
+	g.RegisterContext("aware", func(ctx context.Context) error {
+		<-ctx.Done()
+		hookErr <- ctx.Err()
+		return ctx.Err()
+	}, lifecycle.Timeout(10*time.Millisecond), lifecycle.Priority(lifecycle.PriorityFirst))
+	called := false
+	g.Register("last", func() error {
+		called = true
+		return nil
+	}, lifecycle.Priority(lifecycle.PriorityLast))
+
+	cancel()
+
+	waitDone(t, g)
+	assert.True(t, called)
+	assert.ErrorIs(t, <-hookErr, context.DeadlineExceeded)
+	assert.ElementsMatch(t, []string{"hung", "aware"}, g.TimedOut())
+}
+
+func TestGracefull_ShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := lifecycle.NewGracefullShutdown(ctx)
+	g.SetTimeout(20 * time.Millisecond)
+
+	hung := make(chan struct{})
+	defer close(hung)
+
+	deadlines := make(chan time.Time, 2)
+	g.RegisterContext("hung", func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-hung
+		return nil
+	}, lifecycle.DependsOn("database"))
+	skipped := false
+	g.Register("database", func() error {
+		skipped = true
+		return nil
+	})
+	g.RegisterContext("flush", func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		return nil
+	}, lifecycle.Priority(lifecycle.PriorityLast))
+
+	start := time.Now()
+	cancel()
+
+	waitDone(t, g)
+	assert.WithinDuration(t, start.Add(20*time.Millisecond), <-deadlines, 10*time.Millisecond)
+	assert.False(t, skipped)
+	assert.WithinDuration(t, start.Add(20*time.Millisecond+lifecycle.FlushGracePeriod), <-deadlines, 10*time.Millisecond,
+		"the flush is given a grace period after the deadline")
+	assert.Equal(t, []string{"hung", "database"}, g.TimedOut())
+}
+
+func TestGracefull_Wait(t *testing.T) {
+	t.Run("clean shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		g.Register("database", func() error { return nil })
+		g.Register("http", func() error { return nil }, lifecycle.DependsOn("database"))
+
+		cancel()
+
+		report, err := g.Wait()
+		require.NoError(t, err)
+		require.Len(t, report.Hooks, 2)
+		assert.Equal(t, "http", report.Hooks[0].Name)
+		assert.Equal(t, 0, report.Hooks[0].Stage)
+		assert.Equal(t, "database", report.Hooks[1].Name)
+		assert.Equal(t, 1, report.Hooks[1].Stage)
+		assert.GreaterOrEqual(t, report.Duration, report.Hooks[0].Duration+report.Hooks[1].Duration)
+		<-g.Done()
+	})
+
+	t.Run("unclean shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+		g.SetTimeout(50 * time.Millisecond)
+
+		hung := make(chan struct{})
+		defer close(hung)
+
+		errMock := errors.New("mock error")
+		g.Register("failing", func() error { return errMock })
+		g.Register("hung", func() error {
+			<-hung
+			return nil
+		}, lifecycle.Timeout(10*time.Millisecond))
+		g.Register("ok", func() error { return nil })
+
+		cancel()
+		<-g.Done()
+
+		report, err := g.Wait()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errMock)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "failing: mock error")
+		assert.Contains(t, err.Error(), "hung: abandoned")
+		assert.Equal(t, []string{"hung"}, report.TimedOut())
+
+		require.Len(t, report.Hooks, 3)
+		assert.ErrorIs(t, report.Hooks[0].Err, errMock)
+		assert.False(t, report.Hooks[0].TimedOut)
+		assert.True(t, report.Hooks[1].TimedOut)
+		assert.GreaterOrEqual(t, report.Hooks[1].Duration, 10*time.Millisecond)
+		assert.NoError(t, report.Hooks[2].Err)
+	})
+
+	t.Run("multiple waiters", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+		g.Register("test", func() error { return nil })
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				report, err := g.Wait()
+				assert.NoError(t, err)
+				assert.Len(t, report.Hooks, 1)
+			}()
+		}
+
+		cancel()
+		wg.Wait()
+	})
+}
+
+func TestGracefull_Start(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	t.Run("startup order is the reverse of shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		g.OnStart("logger", noop, lifecycle.Priority(lifecycle.PriorityLast))
+		g.OnStart("database", noop)
+		g.OnStart("cache", noop)
+		g.OnStart("http", noop, lifecycle.DependsOn("database", "cache"))
+
+		assert.Equal(t, [][]string{{"logger"}, {"database", "cache"}, {"http"}}, g.StartStages())
+		assert.NoError(t, g.Start(context.Background()))
+	})
+
+	t.Run("failure stops the next stages", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		errMock := errors.New("mock error")
+		started := false
+		g.OnStart("database", func(context.Context) error { return errMock })
+		g.OnStart("http", func(context.Context) error {
+			started = true
+			return nil
+		}, lifecycle.DependsOn("database"))
+
+		err := g.Start(context.Background())
+		assert.ErrorIs(t, err, errMock)
+		assert.Contains(t, err.Error(), "database: mock error")
+		assert.False(t, started)
+
+		cancel()
+		_, err = g.Wait()
+		assert.NoError(t, err)
+	})
+
+	t.Run("only started components are stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		g.OnStart("database", noop)
+		g.OnStart("http", func(context.Context) error { return errors.New("mock error") }, lifecycle.DependsOn("database"))
+		g.OnStart("never", noop)
+		for _, name := range []string{"database", "http", "standalone"} {
+			g.Register(name, func() error { return nil })
+		}
+
+		assert.Error(t, g.Start(context.Background()))
+
+		cancel()
+		report, err := g.Wait()
+		require.NoError(t, err)
+
+		var stopped []string
+		for _, h := range report.Hooks {
+			stopped = append(stopped, h.Name)
+		}
+		assert.Equal(t, []string{"database", "standalone"}, stopped)
+	})
+
+	t.Run("start hook timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		g.OnStart("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, lifecycle.Timeout(10*time.Millisecond))
+
+		assert.ErrorIs(t, g.Start(context.Background()), context.DeadlineExceeded)
+	})
+
+	t.Run("shutdown during startup", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		var mu sync.Mutex
+		var events []string
+		record := func(event string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}
+
+		g.OnStart("a", func(context.Context) error {
+			// The shutdown completes while a is starting
+			cancel()
+			<-g.Done()
+			record("start a")
+			return nil
+		})
+		g.OnStart("b", func(context.Context) error {
+			record("start b")
+			return nil
+		}, lifecycle.DependsOn("a"))
+		g.Register("a", func() error {
+			record("stop a")
+			return nil
+		})
+		g.Register("b", func() error {
+			record("stop b")
+			return nil
+		}, lifecycle.DependsOn("a"))
+
+		err := g.Start(context.Background())
+		require.ErrorIs(t, err, lifecycle.ErrShuttingDown)
+
+		assert.Equal(t, []string{"start a", "stop a"}, events, "b is not started and a is stopped")
+	})
+
+	t.Run("already started", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		require.NoError(t, g.Start(context.Background()))
+		assert.ErrorIs(t, g.Start(context.Background()), lifecycle.ErrAlreadyStarted)
+		assert.ErrorIs(t, g.OnStart("late", noop), lifecycle.ErrAlreadyStarted)
+	})
+}
+
+func TestGracefull_Panic(t *testing.T) {
+	t.Run("shutdown hook", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		flushed := false
+		g.Register("panicking", func() error { panic("boom") })
+		g.Register("other", func() error { return nil })
+		g.Register("logger", func() error {
+			flushed = true
+			return nil
+		}, lifecycle.Priority(lifecycle.PriorityLast))
+
+		cancel()
+
+		report, err := g.Wait()
+		require.Error(t, err)
+		assert.True(t, flushed)
+
+		var panicErr *lifecycle.PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "lifecycle_test.TestGracefull_Panic")
+		assert.Contains(t, err.Error(), "panicking: panic: boom")
+
+		require.Len(t, report.Hooks, 3)
+		assert.Equal(t, "boom", report.Hooks[0].Panic)
+		assert.False(t, report.Hooks[0].TimedOut)
+		assert.Nil(t, report.Hooks[1].Panic)
+		assert.NoError(t, report.Hooks[1].Err)
+	})
+
+	t.Run("startup hook", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		g.OnStart("panicking", func(context.Context) error { panic(errors.New("boom")) })
+
+		var panicErr *lifecycle.PanicError
+		assert.ErrorAs(t, g.Start(context.Background()), &panicErr)
+	})
+}
+
+func TestGracefull_Done_Broadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := lifecycle.NewGracefullShutdown(ctx)
+	g.Register("test", func() error { return nil })
+
+	cancel()
+
+	// Nobody reads Done while the shutdown completes
+	_, err := g.Wait()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			waitDone(t, g)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGracefull_State(t *testing.T) {
+	t.Run("full lifecycle", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		g := lifecycle.NewGracefullShutdown(ctx)
+		assert.Equal(t, lifecycle.StateCreated, g.State())
+
+		states := make(chan lifecycle.State, 2)
+		g.OnStart("component", func(context.Context) error {
+			states <- g.State()
+			return nil
+		})
+		shuttingDown := make(chan bool, 1)
+		g.Register("component", func() error {
+			states <- g.State()
+			select {
+			case <-g.ShuttingDown():
+				shuttingDown <- true
+			default:
+				shuttingDown <- false
+			}
+			return nil
+		})
+
+		select {
+		case <-g.ShuttingDown():
+			t.Fatal("shutdown has not begun")
+		default:
+		}
+
+		require.NoError(t, g.Start(context.Background()))
+		assert.Equal(t, lifecycle.StateStarting, <-states)
+		assert.Equal(t, lifecycle.StateRunning, g.State())
+
+		cancel()
+		waitDone(t, g)
+		assert.Equal(t, lifecycle.StateStopping, <-states)
+		assert.True(t, <-shuttingDown)
+		assert.Equal(t, lifecycle.StateStopped, g.State())
+	})
+
+	t.Run("startup failure", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		g := lifecycle.NewGracefullShutdown(ctx)
+
+		g.OnStart("failing", func(context.Context) error { return errors.New("mock error") })
+
+		assert.Error(t, g.Start(context.Background()))
+		assert.Equal(t, lifecycle.StateFailed, g.State())
+	})
+}
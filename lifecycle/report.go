@@ -0,0 +1,54 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HookResult is the outcome of a hook executed during graceful shutdown.
+type HookResult struct {
+	// Name is the name the hook was registered with.
+	Name string
+	// Stage is the index of the shutdown stage the hook belongs to.
+	Stage int
+	// Duration is the time spent waiting for the hook.
+	Duration time.Duration
+	// Err is the error returned by the hook, or the reason it was abandoned.
+	Err error
+	// Panic is the value the hook panicked with, if any.
+	Panic any
+	// TimedOut reports whether the hook was abandoned or skipped because of a timeout.
+	TimedOut bool
+}
+
+// Report is the outcome of the graceful shutdown.
+type Report struct {
+	// Hooks holds the result of every hook, in execution order.
+	Hooks []HookResult
+	// Duration is the total duration of the shutdown.
+	Duration time.Duration
+}
+
+// Err returns the errors of the hooks joined with errors.Join,
+// or nil if the shutdown was clean.
+func (r Report) Err() error {
+	var errs []error
+	for _, h := range r.Hooks {
+		if h.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name, h.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TimedOut returns the names of the hooks abandoned or skipped because of a timeout.
+func (r Report) TimedOut() []string {
+	var names []string
+	for _, h := range r.Hooks {
+		if h.TimedOut {
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,168 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ErrAlreadyStarted is returned when starting twice, or registering a startup hook once started.
+var ErrAlreadyStarted = errors.New("lifecycle already started")
+
+// OnStart adds a function to the list of functions to be executed by Start.
+// Startup hooks accept the same options as shutdown hooks and run in the reverse order:
+// a hook is started after the hooks it depends on.
+//
+// A shutdown hook registered under the same name is the matching stop hook:
+// it only runs at shutdown if the startup hook succeeded.
+func (g *Gracefull) OnStart(name string, start func(ctx context.Context) error, opts ...HookOption) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.isShuttingDown() {
+		return fmt.Errorf("failed to register %s: %w", name, ErrShuttingDown)
+	}
+
+	if g.started != nil {
+		return fmt.Errorf("failed to register %s: %w", name, ErrAlreadyStarted)
+	}
+
+	return g.startup.add(newHook(name, start, opts))
+}
+
+// StartStages returns the names of the registered startup hooks grouped by stage, in execution order.
+func (g *Gracefull) StartStages() [][]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return stageNames(g.startStages())
+}
+
+// Start executes the startup hooks stage by stage.
+// The hooks of a stage run concurrently and a stage starts once the previous one succeeded.
+// If a hook fails, Start returns the errors of its stage without starting the next stages;
+// canceling the context given to NewGracefullShutdown then stops the hooks already started.
+// If the shutdown begins during the startup, Start returns an error wrapping ErrShuttingDown
+// without starting the next stages, and stops the hooks whose startup completed too late for the shutdown.
+func (g *Gracefull) Start(ctx context.Context) error {
+	g.mu.Lock()
+	if g.isShuttingDown() {
+		g.mu.Unlock()
+		return ErrShuttingDown
+	}
+	if g.started != nil {
+		g.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	g.started = make(map[string]bool)
+	g.state = StateStarting
+	stages := g.startStages()
+	g.mu.Unlock()
+
+	log.Println("Starting in progress...")
+
+	for _, stage := range stages {
+		g.mu.Lock()
+		shuttingDown := g.isShuttingDown()
+		g.mu.Unlock()
+
+		if shuttingDown {
+			return fmt.Errorf("startup interrupted: %w", ErrShuttingDown)
+		}
+
+		if err := ctx.Err(); err != nil {
+			g.mu.Lock()
+			g.setStartState(StateFailed)
+			g.mu.Unlock()
+			return fmt.Errorf("startup interrupted: %w", err)
+		}
+
+		errs := make([]error, len(stage))
+		wg := &sync.WaitGroup{}
+		for i, h := range stage {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := h.run(ctx); err != nil {
+					log.Printf("Error during startup of %s: %v", h.name, err)
+					errs[i] = fmt.Errorf("%s: %w", h.name, err)
+					return
+				}
+				log.Printf("Startup of %s completed successfully", h.name)
+			}()
+		}
+		wg.Wait()
+
+		err := errors.Join(errs...)
+
+		started := make(map[string]bool)
+		for i, h := range stage {
+			if errs[i] == nil {
+				started[h.name] = true
+			}
+		}
+
+		g.mu.Lock()
+		if g.isShuttingDown() {
+			// The shutdown skipped the hooks of this stage as they were not started yet:
+			// stop the ones started in the meantime so that they do not leak
+			late := g.shutdown.filter(func(h *hook) bool { return started[h.name] })
+			g.mu.Unlock()
+
+			return errors.Join(fmt.Errorf("startup interrupted: %w", ErrShuttingDown), err, stopLate(late))
+		}
+		maps.Copy(g.started, started)
+		if err != nil {
+			g.setStartState(StateFailed)
+		}
+		g.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	g.mu.Lock()
+	g.setStartState(StateRunning)
+	g.mu.Unlock()
+
+	log.Println("Startup is over.")
+
+	return nil
+}
+
+// stopLate runs the stop hooks of the hooks started after the beginning of the shutdown, in shutdown order,
+// and returns their errors.
+func stopLate(hooks *hookSet) error {
+	var errs []error
+	for _, stage := range hooks.stages() {
+		for _, h := range stage {
+			log.Printf("Startup of %s completed after the shutdown began, stopping it", h.name)
+			if _, err := h.run(context.Background()); err != nil {
+				log.Printf("Error during gracefull shutdown of %s: %v", h.name, err)
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startStages returns the startup stages: the shutdown order of the startup hooks, reversed.
+// It must be called with g.mu held.
+func (g *Gracefull) startStages() [][]*hook {
+	stages := g.startup.stages()
+	slices.Reverse(stages)
+	return stages
+}
+
+// setStartState sets the state reached by the startup phase,
+// unless the shutdown has begun in the meantime. It must be called with g.mu held.
+func (g *Gracefull) setStartState(state State) {
+	if g.state == StateStarting {
+		g.state = state
+	}
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/deadelus/go-clean-app/v2/identity"
+)
+
+// RequestIDHeader is the header carrying the request id, read and written by Middleware.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the length of the longest request id accepted by Middleware.
+const maxRequestIDLength = 128
+
+// ContextExtractor returns the fields to attach to the log entries from a context,
+// or nil if the context holds none.
+type ContextExtractor func(ctx context.Context) map[string]any
+
+// extractors is the registry of the context extractors, in registration order.
+var extractors = struct {
+	sync.RWMutex
+	names []string
+	fns   map[string]ContextExtractor
+}{
+	names: []string{"request", "trace", "principal"},
+	fns: map[string]ContextExtractor{
+		"request":   requestFields,
+		"trace":     traceFields,
+		"principal": principalFields,
+	},
+}
+
+// RegisterContextExtractor registers an extractor under name, replacing the extractor
+// already registered under this name, if any, e.g. to take the trace ids from OpenTelemetry:
+//
+//	logger.RegisterContextExtractor("trace", func(ctx context.Context) map[string]any {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return nil
+//		}
+//		return map[string]any{"trace_id": sc.TraceID().String(), "span_id": sc.SpanID().String()}
+//	})
+//
+// The extractors "request", "trace" and "principal" are registered by default.
+// A nil extractor unregisters name.
+func RegisterContextExtractor(name string, fn ContextExtractor) {
+	extractors.Lock()
+	defer extractors.Unlock()
+
+	_, exists := extractors.fns[name]
+	switch {
+	case fn == nil:
+		delete(extractors.fns, name)
+		extractors.names = deleteName(extractors.names, name)
+	case exists:
+		extractors.fns[name] = fn
+	default:
+		extractors.fns[name] = fn
+		extractors.names = append(extractors.names, name)
+	}
+}
+
+// ContextFields returns the fields extracted from ctx by the registered extractors,
+// the later extractors overriding the fields of the earlier ones.
+func ContextFields(ctx context.Context) map[string]any {
+	extractors.RLock()
+	defer extractors.RUnlock()
+
+	fields := make(map[string]any)
+	for _, name := range extractors.names {
+		maps.Copy(fields, extractors.fns[name](ctx))
+	}
+	return fields
+}
+
+// AppendContextFields returns fields followed by the fields extracted from ctx, see ContextFields,
+// for the implementations of the context-aware methods of Logger.
+// The backing array of fields is never written to, as it may be shared by the caller of a variadic method.
+func AppendContextFields(ctx context.Context, fields []any) []any {
+	extracted := ContextFields(ctx)
+	if len(extracted) == 0 {
+		return fields
+	}
+	return slices.Concat(fields, []any{extracted})
+}
+
+// WithContext returns a child logger attaching the fields extracted from ctx, see ContextFields,
+// to every log entry, e.g. for the logs of an HTTP request:
+//
+//	log := logger.WithContext(r.Context(), app.Logger())
+//	log.Info("Order created", map[string]any{"order": id})
+func WithContext(ctx context.Context, l Logger) Logger {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields)
+}
+
+// requestIDKey is the context key of the request id.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the request id carried by ctx, if any.
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// traceKey is the context key of the trace ids.
+type traceKey struct{}
+
+// traceIDs are the ids of a distributed trace.
+type traceIDs struct {
+	traceID, spanID string
+}
+
+// WithTrace returns a copy of ctx carrying the ids of the current trace and span.
+func WithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceIDs{traceID: traceID, spanID: spanID})
+}
+
+// TraceFrom returns the trace and span ids carried by ctx, if any.
+func TraceFrom(ctx context.Context) (traceID, spanID string, ok bool) {
+	ids, ok := ctx.Value(traceKey{}).(traceIDs)
+	return ids.traceID, ids.spanID, ok
+}
+
+// Middleware is an HTTP middleware preparing the request context for the context-aware logging:
+// it carries the request id of the X-Request-ID header, or a new one, which is also set
+// on the response, and the trace ids of the W3C traceparent header, if any.
+// As the header comes from the client, an id longer than 128 bytes or holding characters
+// other than letters, digits, '.', '_' and '-' is replaced by a new one.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := WithRequestID(r.Context(), id)
+
+		if traceID, spanID, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+			ctx = WithTrace(ctx, traceID, spanID)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// NewRequestID returns a random request id, e.g. for a worker to tag the job it takes:
+//
+//	ctx := logger.WithRequestID(ctx, logger.NewRequestID())
+//	log.InfoContext(ctx, "Job started")
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID reports whether id is a request id safe to log and echo, see Middleware.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceparent returns the trace and parent ids of a W3C traceparent header,
+// "version-traceid-parentid-flags".
+func parseTraceparent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	for _, id := range parts[1:3] {
+		if _, err := hex.DecodeString(id); err != nil || strings.Trim(id, "0") == "" {
+			return "", "", false
+		}
+	}
+	return parts[1], parts[2], true
+}
+
+// requestFields extracts the request id.
+func requestFields(ctx context.Context) map[string]any {
+	if id, ok := RequestIDFrom(ctx); ok {
+		return map[string]any{"request_id": id}
+	}
+	return nil
+}
+
+// traceFields extracts the trace ids.
+func traceFields(ctx context.Context) map[string]any {
+	if traceID, spanID, ok := TraceFrom(ctx); ok {
+		return map[string]any{"trace_id": traceID, "span_id": spanID}
+	}
+	return nil
+}
+
+// principalFields extracts the principal, see identity.Principal.Fields.
+func principalFields(ctx context.Context) map[string]any {
+	if p, ok := identity.PrincipalFrom(ctx); ok {
+		return p.Fields()
+	}
+	return nil
+}
+
+// deleteName removes name from names.
+func deleteName(names []string, name string) []string {
+	for i, n := range names {
+		if n == name {
+			return append(names[:i:i], names[i+1:]...)
+		}
+	}
+	return names
+}
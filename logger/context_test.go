@@ -0,0 +1,173 @@
+package logger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deadelus/go-clean-app/v2/identity"
+	"github.com/deadelus/go-clean-app/v2/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextFields(t *testing.T) {
+	t.Run("empty context", func(t *testing.T) {
+		assert.Empty(t, logger.ContextFields(context.Background()))
+	})
+
+	t.Run("built-in extractors", func(t *testing.T) {
+		ctx := logger.WithRequestID(context.Background(), "req-1")
+		ctx = logger.WithTrace(ctx, "trace-1", "span-1")
+		ctx = identity.WithPrincipal(ctx, identity.Principal{Subject: "alice"})
+
+		assert.Equal(t, map[string]any{
+			"request_id": "req-1",
+			"trace_id":   "trace-1",
+			"span_id":    "span-1",
+			"principal":  "alice",
+		}, logger.ContextFields(ctx))
+	})
+
+	t.Run("registered extractors", func(t *testing.T) {
+		type tenantKey struct{}
+		logger.RegisterContextExtractor("tenant", func(ctx context.Context) map[string]any {
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				return map[string]any{"tenant": tenant}
+			}
+			return nil
+		})
+		defer logger.RegisterContextExtractor("tenant", nil)
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+		assert.Equal(t, map[string]any{"tenant": "acme"}, logger.ContextFields(ctx))
+	})
+
+	t.Run("replaced extractors", func(t *testing.T) {
+		logger.RegisterContextExtractor("request", func(ctx context.Context) map[string]any {
+			if id, ok := logger.RequestIDFrom(ctx); ok {
+				return map[string]any{"correlation_id": id}
+			}
+			return nil
+		})
+		defer logger.RegisterContextExtractor("request", func(ctx context.Context) map[string]any {
+			if id, ok := logger.RequestIDFrom(ctx); ok {
+				return map[string]any{"request_id": id}
+			}
+			return nil
+		})
+
+		ctx := logger.WithRequestID(context.Background(), "req-1")
+		assert.Equal(t, map[string]any{"correlation_id": "req-1"}, logger.ContextFields(ctx))
+	})
+}
+
+func TestAppendContextFields(t *testing.T) {
+	t.Run("without fields", func(t *testing.T) {
+		fields := []any{"user", "alice"}
+		assert.Equal(t, fields, logger.AppendContextFields(context.Background(), fields))
+	})
+
+	t.Run("the fields of the caller are not written to", func(t *testing.T) {
+		shared := make([]any, 2, 3)
+		shared[0], shared[1] = "user", "alice"
+		ctx := logger.WithRequestID(context.Background(), "req-1")
+
+		fields := logger.AppendContextFields(ctx, shared)
+
+		assert.Equal(t, []any{"user", "alice", map[string]any{"request_id": "req-1"}}, fields)
+		assert.Nil(t, shared[:3][2], "the spare capacity of the caller is untouched")
+	})
+}
+
+func TestWithContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockLogger := logger.NewMockLogger(ctrl)
+
+	t.Run("without fields", func(t *testing.T) {
+		assert.Same(t, mockLogger, logger.WithContext(context.Background(), mockLogger))
+	})
+
+	t.Run("with fields", func(t *testing.T) {
+		ctx := identity.WithPrincipal(context.Background(), identity.Principal{Subject: "alice", Tenant: "acme"})
+		ctx = logger.WithRequestID(ctx, "req-1")
+		child := logger.NewMockLogger(ctrl)
+
+		mockLogger.EXPECT().With(map[string]any{"principal": "alice", "tenant": "acme", "request_id": "req-1"}).Return(child)
+
+		assert.Same(t, child, logger.WithContext(ctx, mockLogger))
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	serve := func(r *http.Request) (context.Context, *httptest.ResponseRecorder) {
+		var ctx context.Context
+		handler := logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return ctx, w
+	}
+
+	t.Run("propagates the request id and the trace", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(logger.RequestIDHeader, "req-1")
+		r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		ctx, w := serve(r)
+
+		id, ok := logger.RequestIDFrom(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "req-1", id)
+		assert.Equal(t, "req-1", w.Header().Get(logger.RequestIDHeader))
+
+		traceID, spanID, ok := logger.TraceFrom(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+		assert.Equal(t, "00f067aa0ba902b7", spanID)
+	})
+
+	t.Run("generates a request id", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+
+		ctx, w := serve(r)
+
+		id, ok := logger.RequestIDFrom(ctx)
+		require.True(t, ok)
+		assert.Len(t, id, 32)
+		assert.Equal(t, id, w.Header().Get(logger.RequestIDHeader))
+
+		_, _, ok = logger.TraceFrom(ctx)
+		assert.False(t, ok, "an invalid traceparent is ignored")
+	})
+
+	t.Run("replaces an unsafe request id", func(t *testing.T) {
+		for _, unsafe := range []string{
+			strings.Repeat("a", 129),
+			"req-1\nlevel=error msg=forged",
+			"<script>",
+			"req 1",
+		} {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(logger.RequestIDHeader, unsafe)
+
+			ctx, w := serve(r)
+
+			id, _ := logger.RequestIDFrom(ctx)
+			assert.Len(t, id, 32, unsafe)
+			assert.Equal(t, id, w.Header().Get(logger.RequestIDHeader), unsafe)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(logger.RequestIDHeader, "Req_1.a-"+strings.Repeat("0", 120))
+		ctx, _ := serve(r)
+		id, _ := logger.RequestIDFrom(ctx)
+		assert.Equal(t, "Req_1.a-"+strings.Repeat("0", 120), id, "an id of 128 bytes is kept")
+	})
+}
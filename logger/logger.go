@@ -1,10 +1,24 @@
 package logger
 
+import "context"
+
 //go:generate mockgen -source=logger.go -destination=mock_logger.go -package=logger
 type Logger interface {
 	Info(msg string, fields ...any)
 	Error(msg string, fields ...any)
 	Debug(msg string, fields ...any)
 	Warn(msg string, fields ...any)
+	// InfoContext logs an info message with the provided fields and the fields extracted from ctx, see ContextFields.
+	InfoContext(ctx context.Context, msg string, fields ...any)
+	// ErrorContext logs an error message with the provided fields and the fields extracted from ctx.
+	ErrorContext(ctx context.Context, msg string, fields ...any)
+	// DebugContext logs a debug message with the provided fields and the fields extracted from ctx.
+	DebugContext(ctx context.Context, msg string, fields ...any)
+	// WarnContext logs a warning message with the provided fields and the fields extracted from ctx.
+	WarnContext(ctx context.Context, msg string, fields ...any)
 	Close()
+	// With returns a child logger adding fields to every log entry, e.g. a request id.
+	With(fields ...any) Logger
+	// Named returns a child logger whose name is suffixed with name, e.g. a component name.
+	Named(name string) Logger
 }
@@ -0,0 +1,73 @@
+package sloglogger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+
+	"github.com/deadelus/go-clean-app/v2/application"
+	"github.com/deadelus/go-clean-app/v2/lifecycle"
+)
+
+// NewHandler is a hook for the handler used by SetSlogLogger when none is given, can be replaced in tests.
+// It writes JSON to the standard output, at debug level in debug mode, and text to the standard error in CLI mode.
+var NewHandler = func(e *application.Engine) slog.Handler {
+	level := slog.LevelInfo
+	if e.Debug() {
+		level = slog.LevelDebug
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	if e.RunMode() == application.ModeCLI {
+		return slog.NewTextHandler(os.Stderr, opts)
+	}
+
+	return slog.NewJSONHandler(os.Stdout, opts).WithAttrs([]slog.Attr{
+		slog.String("app_version", e.Version()),
+		slog.String("app_env", e.Env()),
+		slog.Bool("app_debug", e.Debug()),
+		slog.String("go_version", runtime.Version()),
+	})
+}
+
+// SetSlogLogger sets a logger writing to handler for the Engine, named after the application,
+// or to the handler returned by NewHandler if handler is nil, e.g. to log through zap:
+//
+//	app, err := application.New(sloglogger.SetSlogLogger(zaplogger.NewSlogHandler(zl)))
+//
+// A handler naming its entries itself keeps the name of its logger, e.g. the one of the zap logger.
+// In test mode, the logger discards everything.
+// It fails if the close function of the logger cannot be registered.
+func SetSlogLogger(handler slog.Handler) application.Option {
+	return application.OptionE(func(e *application.Engine) error {
+		if e.RunMode() == application.ModeTest {
+			e.SetLogger(NewLogger(slog.DiscardHandler))
+			return nil
+		}
+
+		if handler == nil {
+			handler = NewHandler(e)
+		}
+
+		logger := NewLogger(handler)
+		if _, ok := handler.(namedHandler); !ok {
+			logger.name = e.Name()
+		}
+
+		// Set the logger in the Engine
+		e.SetLogger(logger)
+
+		// Register the close function with the graceful shutdown manager,
+		// flushed after every other hook so their logs are not lost
+		closeLogger := func() error {
+			logger.Close()
+			return nil
+		}
+		if err := e.Gracefull().Register("sloglogger", closeLogger, lifecycle.Priority(lifecycle.PriorityLast)); err != nil {
+			return fmt.Errorf("failed to register slog logger for graceful shutdown: %w", err)
+		}
+
+		return nil
+	}).Option()
+}
@@ -0,0 +1,153 @@
+// Package sloglogger provides a logger implementation on top of any log/slog handler.
+package sloglogger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/deadelus/go-clean-app/v2/logger"
+)
+
+// NameKey is the key of the logger name, as set by Named, like with zap.
+const NameKey = "logger"
+
+// namedHandler is implemented by the handlers naming their entries themselves,
+// such as the one returned by zaplogger.NewSlogHandler.
+type namedHandler interface {
+	Named(name string) slog.Handler
+}
+
+// SlogLogger is a logger implementation using a log/slog logger.
+// It implements the Logger interface defined in pkg/logger/logger.go.
+type SlogLogger struct {
+	Logger *slog.Logger
+	name   string
+}
+
+// Ensure that SlogLogger implements the Logger interface.
+var _ logger.Logger = &SlogLogger{}
+
+// NewLogger creates a new SlogLogger instance writing to handler.
+func NewLogger(handler slog.Handler) *SlogLogger {
+	return &SlogLogger{Logger: slog.New(handler)}
+}
+
+// Info logs an info message with the provided fields.
+func (s *SlogLogger) Info(msg string, fields ...any) {
+	s.log(context.Background(), slog.LevelInfo, msg, fields)
+}
+
+// Error logs an error message with the provided fields.
+func (s *SlogLogger) Error(msg string, fields ...any) {
+	s.log(context.Background(), slog.LevelError, msg, fields)
+}
+
+// Debug logs a debug message with the provided fields.
+func (s *SlogLogger) Debug(msg string, fields ...any) {
+	s.log(context.Background(), slog.LevelDebug, msg, fields)
+}
+
+// Warn logs a warning message with the provided fields.
+func (s *SlogLogger) Warn(msg string, fields ...any) {
+	s.log(context.Background(), slog.LevelWarn, msg, fields)
+}
+
+// InfoContext logs an info message with the provided fields and the fields extracted from ctx.
+func (s *SlogLogger) InfoContext(ctx context.Context, msg string, fields ...any) {
+	s.log(ctx, slog.LevelInfo, msg, logger.AppendContextFields(ctx, fields))
+}
+
+// ErrorContext logs an error message with the provided fields and the fields extracted from ctx.
+func (s *SlogLogger) ErrorContext(ctx context.Context, msg string, fields ...any) {
+	s.log(ctx, slog.LevelError, msg, logger.AppendContextFields(ctx, fields))
+}
+
+// DebugContext logs a debug message with the provided fields and the fields extracted from ctx.
+func (s *SlogLogger) DebugContext(ctx context.Context, msg string, fields ...any) {
+	s.log(ctx, slog.LevelDebug, msg, logger.AppendContextFields(ctx, fields))
+}
+
+// WarnContext logs a warning message with the provided fields and the fields extracted from ctx.
+func (s *SlogLogger) WarnContext(ctx context.Context, msg string, fields ...any) {
+	s.log(ctx, slog.LevelWarn, msg, logger.AppendContextFields(ctx, fields))
+}
+
+// With returns a child logger adding the provided fields to every log entry.
+func (s *SlogLogger) With(fields ...any) logger.Logger {
+	return &SlogLogger{Logger: s.Logger.With(ConvertToArgs(fields...)...), name: s.name}
+}
+
+// Named returns a child logger whose name is suffixed with name, separated by a period.
+// The name is given to the handler if it names its entries itself, and logged under NameKey otherwise.
+func (s *SlogLogger) Named(name string) logger.Logger {
+	if name == "" {
+		return s
+	}
+	if h, ok := s.Logger.Handler().(namedHandler); ok {
+		return &SlogLogger{Logger: slog.New(h.Named(name))}
+	}
+
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return &SlogLogger{Logger: s.Logger, name: name}
+}
+
+// Close flushes the handler if it supports it, e.g. a handler writing to a zap core.
+func (s *SlogLogger) Close() {
+	if h, ok := s.Logger.Handler().(interface{ Sync() error }); ok {
+		h.Sync()
+	}
+}
+
+// log writes a record at level, with the caller of the logging method as source.
+func (s *SlogLogger) log(ctx context.Context, level slog.Level, msg string, fields []any) {
+	if !s.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	// Skip runtime.Callers, log and the logging method
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if s.name != "" {
+		r.AddAttrs(slog.String(NameKey, s.name))
+	}
+	r.Add(ConvertToArgs(fields...)...)
+
+	s.Logger.Handler().Handle(ctx, r)
+}
+
+// ConvertToArgs converts the fields accepted by the Logger interface to slog arguments:
+// maps are expanded to attributes sorted by key and errors become an "error" attribute,
+// while slog.Attr values and alternating keys and values are kept, so that
+// slog reports a value not preceded by a key under "!BADKEY".
+func ConvertToArgs(fields ...any) []any {
+	args := make([]any, 0, len(fields))
+
+	for i := 0; i < len(fields); i++ {
+		switch field := fields[i].(type) {
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(field)) {
+				args = append(args, slog.Any(key, field[key]))
+			}
+		case error:
+			args = append(args, slog.Any("error", field))
+		case string:
+			args = append(args, field)
+			if i+1 < len(fields) {
+				args = append(args, fields[i+1])
+				i++
+			}
+		default:
+			args = append(args, field)
+		}
+	}
+
+	return args
+}
@@ -0,0 +1,208 @@
+package sloglogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/deadelus/go-clean-app/v2/application"
+	"github.com/deadelus/go-clean-app/v2/logger"
+	"github.com/deadelus/go-clean-app/v2/logger/sloglogger"
+	"github.com/deadelus/go-clean-app/v2/logger/zaplogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newBufferLogger returns a logger writing JSON to a buffer, and a function decoding its entries.
+func newBufferLogger(t *testing.T) (*sloglogger.SlogLogger, func() []map[string]any) {
+	var buffer bytes.Buffer
+	handler := slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: true})
+
+	entries := func() []map[string]any {
+		var entries []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n")) {
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(line, &entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	return sloglogger.NewLogger(handler), entries
+}
+
+func TestSlogLogger_LoggingMethods(t *testing.T) {
+	log, entries := newBufferLogger(t)
+
+	log.Info("info", "user", "alice")
+	log.Error("error", assert.AnError)
+	log.Debug("debug", map[string]any{"order": 42})
+	log.Warn("warn", slog.Int("status", 200))
+	log.Close()
+
+	lines := entries()
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, "INFO", lines[0]["level"])
+	assert.Equal(t, "info", lines[0]["msg"])
+	assert.Equal(t, "alice", lines[0]["user"])
+
+	assert.Equal(t, "ERROR", lines[1]["level"])
+	assert.Equal(t, assert.AnError.Error(), lines[1]["error"])
+
+	assert.Equal(t, "DEBUG", lines[2]["level"])
+	assert.Equal(t, float64(42), lines[2]["order"])
+
+	assert.Equal(t, "WARN", lines[3]["level"])
+	assert.Equal(t, float64(200), lines[3]["status"])
+
+	source, ok := lines[0]["source"].(map[string]any)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(source["file"].(string), "sloglogger_test.go"), "the source is the caller of the logger")
+}
+
+func TestSlogLogger_ChildLoggers(t *testing.T) {
+	log, entries := newBufferLogger(t)
+
+	child := log.Named("http").With(map[string]any{"request_id": "abc"}).Named("handler")
+	child.Info("handled", "status", 200)
+	log.Info("unscoped")
+
+	lines := entries()
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "http.handler", lines[0][sloglogger.NameKey])
+	assert.Equal(t, "abc", lines[0]["request_id"])
+	assert.Equal(t, float64(200), lines[0]["status"])
+
+	assert.NotContains(t, lines[1], sloglogger.NameKey)
+	assert.NotContains(t, lines[1], "request_id")
+}
+
+func TestSlogLogger_ContextMethods(t *testing.T) {
+	log, entries := newBufferLogger(t)
+
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	ctx = logger.WithTrace(ctx, "trace-1", "span-1")
+
+	log.InfoContext(ctx, "info", "status", 200)
+	log.ErrorContext(ctx, "error")
+	log.DebugContext(ctx, "debug")
+	log.WarnContext(ctx, "warn")
+	log.InfoContext(context.Background(), "no context")
+
+	lines := entries()
+	require.Len(t, lines, 5)
+
+	for i, level := range []string{"INFO", "ERROR", "DEBUG", "WARN"} {
+		assert.Equal(t, level, lines[i]["level"])
+		assert.Equal(t, "req-1", lines[i]["request_id"])
+		assert.Equal(t, "trace-1", lines[i]["trace_id"])
+		assert.Equal(t, "span-1", lines[i]["span_id"])
+	}
+	assert.Equal(t, float64(200), lines[0]["status"])
+	assert.NotContains(t, lines[4], "request_id")
+}
+
+func TestConvertToArgs(t *testing.T) {
+	t.Run("with key/value pairs", func(t *testing.T) {
+		args := sloglogger.ConvertToArgs("user", "alice", "cause", assert.AnError)
+		assert.Equal(t, []any{"user", "alice", "cause", assert.AnError}, args)
+	})
+
+	t.Run("with map", func(t *testing.T) {
+		args := sloglogger.ConvertToArgs(map[string]any{"b": 2, "a": 1})
+		assert.Equal(t, []any{slog.Any("a", 1), slog.Any("b", 2)}, args)
+	})
+
+	t.Run("with error", func(t *testing.T) {
+		args := sloglogger.ConvertToArgs(assert.AnError, slog.Int("status", 200))
+		assert.Equal(t, []any{slog.Any("error", assert.AnError), slog.Int("status", 200)}, args)
+	})
+
+	t.Run("with odd trailing value", func(t *testing.T) {
+		var buffer bytes.Buffer
+		sloglogger.NewLogger(slog.NewJSONHandler(&buffer, nil)).Info("odd", 42)
+		assert.Contains(t, buffer.String(), `"!BADKEY":42`)
+	})
+}
+
+func TestSetSlogLogger(t *testing.T) {
+	t.Run("with handler", func(t *testing.T) {
+		var buffer bytes.Buffer
+		app, err := application.New(
+			application.AppName("TEST"),
+			sloglogger.SetSlogLogger(slog.NewJSONHandler(&buffer, nil)),
+		)
+		require.NoError(t, err)
+
+		app.Logger().Info("hello")
+		assert.Contains(t, buffer.String(), `"logger":"TEST"`)
+
+		assert.True(t, app.Gracefull().Unregister("sloglogger"), "the close function is registered")
+	})
+
+	t.Run("zap handler", func(t *testing.T) {
+		var buffer bytes.Buffer
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buffer), zapcore.InfoLevel)
+		zl, _, _ := zaplogger.GetFromExternalLogger(zap.New(core).Named("TEST"))
+
+		app, err := application.New(
+			application.AppName("TEST"),
+			sloglogger.SetSlogLogger(zaplogger.NewSlogHandler(zl)),
+		)
+		require.NoError(t, err)
+
+		app.Logger().Info("hello")
+		app.Logger().Named("http").With("k", 1).Info("hello")
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, 1, strings.Count(lines[0], `"logger"`), "the name is logged once")
+		assert.Contains(t, lines[0], `"logger":"TEST"`)
+		assert.Equal(t, 1, strings.Count(lines[1], `"logger"`), "the name is logged once")
+		assert.Contains(t, lines[1], `"logger":"TEST.http"`)
+		assert.Contains(t, lines[1], `"k":1`)
+	})
+
+	t.Run("default handler", func(t *testing.T) {
+		original := sloglogger.NewHandler
+		defer func() { sloglogger.NewHandler = original }()
+
+		var buffer bytes.Buffer
+		sloglogger.NewHandler = func(e *application.Engine) slog.Handler {
+			return slog.NewTextHandler(&buffer, nil)
+		}
+
+		app, err := application.New(sloglogger.SetSlogLogger(nil))
+		require.NoError(t, err)
+
+		app.Logger().Info("hello")
+		assert.Contains(t, buffer.String(), "msg=hello")
+	})
+
+	t.Run("test mode", func(t *testing.T) {
+		original := sloglogger.NewHandler
+		defer func() { sloglogger.NewHandler = original }()
+
+		sloglogger.NewHandler = func(e *application.Engine) slog.Handler {
+			t.Fatal("no handler is created in test mode")
+			return nil
+		}
+
+		var buffer bytes.Buffer
+		app, err := application.New(
+			application.WithRunMode(application.ModeTest),
+			sloglogger.SetSlogLogger(slog.NewJSONHandler(&buffer, nil)),
+		)
+		require.NoError(t, err)
+
+		app.Logger().Info("hello")
+		assert.Empty(t, buffer.String())
+	})
+}
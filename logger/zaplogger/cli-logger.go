@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/deadelus/go-clean-app/v2/application"
+	"github.com/deadelus/go-clean-app/v2/lifecycle"
 	"go.uber.org/zap"
 )
 
@@ -11,8 +12,15 @@ import (
 var NewZapLoggerForCLI = zap.NewDevelopmentConfig
 
 // SetZapLoggerForCLI sets the logger for the Engine specifically for CLI applications.
+// In test mode, the logger discards everything.
+// It fails if the logger cannot be created or its close function cannot be registered.
 func SetZapLoggerForCLI() application.Option {
-	return func(e *application.Engine) {
+	return application.OptionE(func(e *application.Engine) error {
+		if e.RunMode() == application.ModeTest {
+			e.SetLogger(&ZapLogger{Logger: zap.NewNop()})
+			return nil
+		}
+
 		config := NewZapLoggerForCLI()
 		l, err := config.Build(
 			zap.AddStacktrace(zap.PanicLevel),
@@ -20,7 +28,7 @@ func SetZapLoggerForCLI() application.Option {
 		)
 
 		if err != nil {
-			panic(fmt.Errorf("failed to create zap logger for CLI: %w", err))
+			return fmt.Errorf("failed to create zap logger for CLI: %w", err)
 		}
 
 		logger, closeLogger, _ := GetFromExternalLogger(l)
@@ -28,9 +36,12 @@ func SetZapLoggerForCLI() application.Option {
 		// Set the logger in the Engine
 		e.SetLogger(logger)
 
-		// Register the close function with the graceful shutdown manager
-		if err := e.Gracefull().Register("zaplogger-cli", closeLogger); err != nil {
-			panic(fmt.Errorf("failed to register zap logger for CLI graceful shutdown: %w", err))
+		// Register the close function with the graceful shutdown manager,
+		// flushed after every other hook so their logs are not lost
+		if err := e.Gracefull().Register("zaplogger-cli", closeLogger, lifecycle.Priority(lifecycle.PriorityLast)); err != nil {
+			return fmt.Errorf("failed to register zap logger for CLI graceful shutdown: %w", err)
 		}
-	}
+
+		return nil
+	}).Option()
 }
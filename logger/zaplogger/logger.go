@@ -4,15 +4,28 @@ import (
 	"fmt"
 
 	"github.com/deadelus/go-clean-app/v2/application"
+	"github.com/deadelus/go-clean-app/v2/lifecycle"
+	"go.uber.org/zap"
 )
 
 // SetLogger sets the logger for the Engine.
 // NewZapLogger is a hook for zaplogger.NewLogger, can be replaced in tests.
 var NewZapLogger = NewLogger
 
-// SetZapLogger sets the logger for the Engine.
+// SetZapLogger sets the logger for the Engine, adapted to its run mode:
+// a human-readable logger in CLI mode, like SetZapLoggerForCLI, and a logger discarding everything in test mode.
+// It fails if the logger cannot be created or its close function cannot be registered.
 func SetZapLogger() application.Option {
-	return func(e *application.Engine) {
+	return application.OptionE(func(e *application.Engine) error {
+		switch e.RunMode() {
+		case application.ModeCLI:
+			SetZapLoggerForCLI()(e)
+			return nil
+		case application.ModeTest:
+			e.SetLogger(&ZapLogger{Logger: zap.NewNop()})
+			return nil
+		}
+
 		logger, closeLogger, err := NewZapLogger(
 			e.Name(),
 			e.Version(),
@@ -21,15 +34,18 @@ func SetZapLogger() application.Option {
 		)
 
 		if err != nil {
-			panic(fmt.Errorf("failed to create zap logger: %w", err))
+			return fmt.Errorf("failed to create zap logger: %w", err)
 		}
 
 		// Set the logger in the Engine
 		e.SetLogger(logger)
 
-		// Register the close function with the graceful shutdown manager
-		if err := e.Gracefull().Register("zaplogger", closeLogger); err != nil {
-			panic(fmt.Errorf("failed to register zap logger for graceful shutdown: %w", err))
+		// Register the close function with the graceful shutdown manager,
+		// flushed after every other hook so their logs are not lost
+		if err := e.Gracefull().Register("zaplogger", closeLogger, lifecycle.Priority(lifecycle.PriorityLast)); err != nil {
+			return fmt.Errorf("failed to register zap logger for graceful shutdown: %w", err)
 		}
-	}
+
+		return nil
+	}).Option()
 }
@@ -0,0 +1,177 @@
+package zaplogger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler is a slog.Handler writing to a zap logger.
+type slogHandler struct {
+	logger *zap.Logger
+	// groups are the groups opened by WithGroup and not yet holding any attribute,
+	// omitted from the entries without attributes.
+	groups []string
+}
+
+// NewSlogHandler returns a slog.Handler writing to z, so that the libraries logging through log/slog
+// share the sink of the application:
+//
+//	slog.SetDefault(slog.New(zaplogger.NewSlogHandler(zl)))
+//
+// The slog groups become nested objects and the levels are mapped to the closest zap level below.
+func NewSlogHandler(z *ZapLogger) slog.Handler {
+	return &slogHandler{logger: z.Logger}
+}
+
+// Enabled reports whether the zap logger writes the entries at level.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(zapLevel(level))
+}
+
+// Handle writes the record to the zap logger.
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	ce := h.logger.Check(zapLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	// Report the caller of slog rather than the handler, if the zap logger reports callers
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+
+	fields := make([]zap.Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, a)
+		return true
+	})
+
+	if len(fields) > 0 {
+		fields = append(namespaces(h.groups), fields...)
+	}
+	ce.Write(fields...)
+
+	return nil
+}
+
+// WithAttrs returns a handler adding attrs to every entry, in the current group.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zap.Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+
+	return &slogHandler{logger: h.logger.With(append(namespaces(h.groups), fields...)...)}
+}
+
+// WithGroup returns a handler nesting the attributes added afterwards under name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{logger: h.logger, groups: append(h.groups[:len(h.groups):len(h.groups)], name)}
+}
+
+// Named returns a handler whose entries are logged by a child of the zap logger named name,
+// so that sloglogger.SlogLogger.Named does not log the name twice.
+func (h *slogHandler) Named(name string) slog.Handler {
+	return &slogHandler{logger: h.logger.Named(name), groups: h.groups}
+}
+
+// Sync flushes the zap logger, see sloglogger.SlogLogger.Close.
+func (h *slogHandler) Sync() error {
+	return h.logger.Sync()
+}
+
+// zapLevel returns the zap level of a slog level, the closest one below for the custom levels.
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+// namespaces returns the zap namespaces opening groups.
+func namespaces(groups []string) []zap.Field {
+	fields := make([]zap.Field, 0, len(groups))
+	for _, group := range groups {
+		fields = append(fields, zap.Namespace(group))
+	}
+	return fields
+}
+
+// appendAttr appends the zap field of a slog attribute to fields, following the rules of slog.Handler:
+// the empty attributes and groups are ignored and the attributes of groups without key are inlined.
+func appendAttr(fields []zap.Field, a slog.Attr) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, attr := range attrs {
+				fields = appendAttr(fields, attr)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, groupMarshaler(attrs)))
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	default:
+		if err, ok := a.Value.Any().(error); ok {
+			return append(fields, zap.NamedError(a.Key, err))
+		}
+		return append(fields, zap.Any(a.Key, a.Value.Any()))
+	}
+}
+
+// groupMarshaler encodes the attributes of a slog group as a zap object.
+type groupMarshaler []slog.Attr
+
+// MarshalLogObject adds the attributes to enc.
+func (g groupMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, a := range g {
+		fields = appendAttr(fields, a)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return nil
+}
@@ -2,9 +2,13 @@
 package zaplogger
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"runtime"
+	"slices"
 
+	"github.com/deadelus/go-clean-app/v2/logger"
 	"go.uber.org/zap"
 )
 
@@ -14,6 +18,9 @@ type ZapLogger struct {
 	Logger *zap.Logger
 }
 
+// Ensure that ZapLogger implements the Logger interface.
+var _ logger.Logger = &ZapLogger{}
+
 type Gracefull func() error
 
 // BuildConfig is a helper to allow testing config.Build() errors
@@ -106,6 +113,36 @@ func (z *ZapLogger) Warn(msg string, fields ...any) {
 	z.Logger.Warn(msg, ConvertToZapFields(fields...)...)
 }
 
+// InfoContext logs an info message with the provided fields and the fields extracted from ctx.
+func (z *ZapLogger) InfoContext(ctx context.Context, msg string, fields ...any) {
+	z.Logger.Info(msg, ConvertToZapFields(logger.AppendContextFields(ctx, fields)...)...)
+}
+
+// ErrorContext logs an error message with the provided fields and the fields extracted from ctx.
+func (z *ZapLogger) ErrorContext(ctx context.Context, msg string, fields ...any) {
+	z.Logger.Error(msg, ConvertToZapFields(logger.AppendContextFields(ctx, fields)...)...)
+}
+
+// DebugContext logs a debug message with the provided fields and the fields extracted from ctx.
+func (z *ZapLogger) DebugContext(ctx context.Context, msg string, fields ...any) {
+	z.Logger.Debug(msg, ConvertToZapFields(logger.AppendContextFields(ctx, fields)...)...)
+}
+
+// WarnContext logs a warning message with the provided fields and the fields extracted from ctx.
+func (z *ZapLogger) WarnContext(ctx context.Context, msg string, fields ...any) {
+	z.Logger.Warn(msg, ConvertToZapFields(logger.AppendContextFields(ctx, fields)...)...)
+}
+
+// With returns a child logger adding the provided fields to every log entry.
+func (z *ZapLogger) With(fields ...any) logger.Logger {
+	return &ZapLogger{Logger: z.Logger.With(ConvertToZapFields(fields...)...)}
+}
+
+// Named returns a child logger whose name is suffixed with name, separated by a period.
+func (z *ZapLogger) Named(name string) logger.Logger {
+	return &ZapLogger{Logger: z.Logger.Named(name)}
+}
+
 // Close flushes the logger and releases any resources.
 // It ensures that all buffered log entries are written out.
 // If there is an error during flushing, it logs the error using the zap logger.
@@ -114,52 +151,69 @@ func (z *ZapLogger) Close() {
 	z.Logger.Sync()
 }
 
-// ConvertToZapFields converts various field types to zap.Field
+// BadKey is the key of the values not preceded by a key, e.g. the odd trailing value of the key/value pairs.
+const BadKey = "!BADKEY"
+
+// ConvertToZapFields converts various field types to zap.Field:
+// zap.Field values are kept, maps are converted by ConvertMapToZapFields,
+// errors become zap.Error fields and strings are keys followed by their value, like with log/slog:
+//
+//	logger.Info("Order created", "order", 42, "user", "alice", err)
+//
+// A value not preceded by a key is reported under BadKey.
 func ConvertToZapFields(fields ...any) []zap.Field {
 	var zapFields []zap.Field
 
-	for _, field := range fields {
-		// Si c'est déjà un zap.Field, on l'ajoute directement
-		if f, ok := field.(zap.Field); ok {
-			zapFields = append(zapFields, f)
-			continue
-		}
-
-		// Si c'est une map[string]any
-		if m, ok := field.(map[string]any); ok {
-			zapFields = append(zapFields, ConvertMapToZapFields(m)...)
-			continue
+	for i := 0; i < len(fields); i++ {
+		switch field := fields[i].(type) {
+		case zap.Field:
+			zapFields = append(zapFields, field)
+		case map[string]any:
+			zapFields = append(zapFields, ConvertMapToZapFields(field)...)
+		case error:
+			zapFields = append(zapFields, zap.Error(field))
+		case string:
+			if i+1 == len(fields) {
+				zapFields = append(zapFields, zap.String(BadKey, field))
+				continue
+			}
+			zapFields = append(zapFields, toZapField(field, fields[i+1]))
+			i++
+		default:
+			zapFields = append(zapFields, zap.Any(BadKey, field))
 		}
-
-		// Par défaut, on ajoute comme zap.Any
-		zapFields = append(zapFields, zap.Any("field", field))
 	}
 
 	return zapFields
 }
 
-// ConvertMapToZapFields convertit une map en slice de zap.Field
+// ConvertMapToZapFields converts a map to zap.Field values, sorted by key so the output is deterministic.
 func ConvertMapToZapFields(m map[string]interface{}) []zap.Field {
-	var fields []zap.Field
+	fields := make([]zap.Field, 0, len(m))
 
-	for key, value := range m {
-		switch v := value.(type) {
-		case error:
-			fields = append(fields, zap.Error(v))
-		case string:
-			fields = append(fields, zap.String(key, v))
-		case int:
-			fields = append(fields, zap.Int(key, v))
-		case int64:
-			fields = append(fields, zap.Int64(key, v))
-		case float64:
-			fields = append(fields, zap.Float64(key, v))
-		case bool:
-			fields = append(fields, zap.Bool(key, v))
-		default:
-			fields = append(fields, zap.Any(key, v))
-		}
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		fields = append(fields, toZapField(key, m[key]))
 	}
 
 	return fields
 }
+
+// toZapField converts a key/value pair to a zap.Field.
+func toZapField(key string, value any) zap.Field {
+	switch v := value.(type) {
+	case error:
+		return zap.NamedError(key, v)
+	case string:
+		return zap.String(key, v)
+	case int:
+		return zap.Int(key, v)
+	case int64:
+		return zap.Int64(key, v)
+	case float64:
+		return zap.Float64(key, v)
+	case bool:
+		return zap.Bool(key, v)
+	default:
+		return zap.Any(key, v)
+	}
+}
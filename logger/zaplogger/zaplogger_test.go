@@ -2,10 +2,15 @@ package zaplogger_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/deadelus/go-clean-app/v2/identity"
+	"github.com/deadelus/go-clean-app/v2/logger"
 	"github.com/deadelus/go-clean-app/v2/logger/zaplogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -99,6 +104,108 @@ func TestZapLogger_LoggingMethods(t *testing.T) {
 	}
 }
 
+func TestZapLogger_ChildLoggers(t *testing.T) {
+	var buffer bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buffer), zapcore.DebugLevel)
+	logger, _, _ := zaplogger.GetFromExternalLogger(zap.New(core).Named("app"))
+
+	child := logger.Named("http").With(map[string]any{"request_id": "abc"}).Named("handler")
+	child.Info("handled", zap.Int("status", 200))
+	logger.Info("unscoped")
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var scoped, unscoped map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &scoped))
+	require.NoError(t, json.Unmarshal(lines[1], &unscoped))
+
+	assert.Equal(t, "app.http.handler", scoped["logger"])
+	assert.Equal(t, "abc", scoped["request_id"])
+	assert.Equal(t, float64(200), scoped["status"])
+
+	assert.Equal(t, "app", unscoped["logger"])
+	assert.NotContains(t, unscoped, "request_id")
+}
+
+func TestZapLogger_ContextMethods(t *testing.T) {
+	var buffer bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buffer), zapcore.DebugLevel)
+	log, _, _ := zaplogger.GetFromExternalLogger(zap.New(core))
+
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	ctx = logger.WithTrace(ctx, "trace-1", "span-1")
+	ctx = identity.WithPrincipal(ctx, identity.Principal{Subject: "alice"})
+
+	log.InfoContext(ctx, "info", zap.Int("status", 200))
+	log.ErrorContext(ctx, "error")
+	log.DebugContext(ctx, "debug")
+	log.WarnContext(ctx, "warn")
+	log.InfoContext(context.Background(), "no context")
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	require.Len(t, lines, 5)
+
+	for i, level := range []string{"info", "error", "debug", "warn"} {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(lines[i], &entry))
+
+		assert.Equal(t, level, entry["level"])
+		assert.Equal(t, "req-1", entry["request_id"])
+		assert.Equal(t, "trace-1", entry["trace_id"])
+		assert.Equal(t, "span-1", entry["span_id"])
+		assert.Equal(t, "alice", entry["principal"])
+	}
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &entry))
+	assert.Equal(t, float64(200), entry["status"])
+
+	var unscoped map[string]any
+	require.NoError(t, json.Unmarshal(lines[4], &unscoped))
+	assert.NotContains(t, unscoped, "request_id")
+}
+
+func TestNewSlogHandler(t *testing.T) {
+	var buffer bytes.Buffer
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(&buffer), zapcore.InfoLevel)
+	zl, _, _ := zaplogger.GetFromExternalLogger(zap.New(core, zap.AddCaller()).Named("app"))
+
+	log := slog.New(zaplogger.NewSlogHandler(zl))
+	log.Debug("filtered")
+	log.Info("info", "user", "alice", "order", 42, "cause", assert.AnError)
+	log.With("request_id", "abc").WithGroup("http").Warn("warn", slog.Group("req", "method", "GET"), slog.Group("", "inlined", true))
+	log.WithGroup("empty").Error("error")
+	log.Log(context.Background(), slog.LevelError+4, "custom level", slog.Duration("elapsed", time.Second))
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+
+	entries := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal(line, &entries[i]))
+	}
+
+	assert.Equal(t, "info", entries[0]["level"])
+	assert.Equal(t, "app", entries[0]["logger"])
+	assert.Equal(t, "alice", entries[0]["user"])
+	assert.Equal(t, float64(42), entries[0]["order"])
+	assert.Equal(t, assert.AnError.Error(), entries[0]["cause"])
+	assert.Contains(t, entries[0]["caller"], "zaplogger_test.go", "the caller is the one of slog")
+
+	assert.Equal(t, "warn", entries[1]["level"])
+	assert.Equal(t, "abc", entries[1]["request_id"])
+	assert.Equal(t, map[string]any{"req": map[string]any{"method": "GET"}, "inlined": true}, entries[1]["http"])
+
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.NotContains(t, entries[2], "empty", "groups without attributes are omitted")
+
+	assert.Equal(t, "error", entries[3]["level"])
+	assert.Equal(t, float64(1), entries[3]["elapsed"])
+}
+
 func TestConvertToZapFields(t *testing.T) {
 	t.Run("with zap.Field", func(t *testing.T) {
 		fields := []any{zap.String("key", "value")}
@@ -110,25 +217,41 @@ func TestConvertToZapFields(t *testing.T) {
 	t.Run("with map[string]interface{}", func(t *testing.T) {
 		fields := []any{map[string]interface{}{"key": "value", "num": 123}}
 		zapFields := zaplogger.ConvertToZapFields(fields...)
-		assert.Len(t, zapFields, 2)
-		// Order is not guaranteed in maps
-		assert.Contains(t, zapFields, zap.String("key", "value"))
-		assert.Contains(t, zapFields, zap.Int("num", 123))
+		assert.Equal(t, []zap.Field{zap.String("key", "value"), zap.Int("num", 123)}, zapFields)
 	})
 
 	t.Run("with map[string]any", func(t *testing.T) {
 		fields := []any{map[string]any{"key": "value", "bool": true}}
 		zapFields := zaplogger.ConvertToZapFields(fields...)
-		assert.Len(t, zapFields, 2)
-		assert.Contains(t, zapFields, zap.String("key", "value"))
-		assert.Contains(t, zapFields, zap.Bool("bool", true))
+		assert.Equal(t, []zap.Field{zap.Bool("bool", true), zap.String("key", "value")}, zapFields)
+	})
+
+	t.Run("with key/value pairs", func(t *testing.T) {
+		fields := []any{"user", "alice", "order", 42, "cause", assert.AnError}
+		zapFields := zaplogger.ConvertToZapFields(fields...)
+		assert.Equal(t, []zap.Field{
+			zap.String("user", "alice"),
+			zap.Int("order", 42),
+			zap.NamedError("cause", assert.AnError),
+		}, zapFields)
 	})
 
-	t.Run("with other type", func(t *testing.T) {
+	t.Run("with error", func(t *testing.T) {
+		fields := []any{assert.AnError, "user", "alice"}
+		zapFields := zaplogger.ConvertToZapFields(fields...)
+		assert.Equal(t, []zap.Field{zap.Error(assert.AnError), zap.String("user", "alice")}, zapFields)
+	})
+
+	t.Run("with odd trailing value", func(t *testing.T) {
 		fields := []any{"just a string"}
 		zapFields := zaplogger.ConvertToZapFields(fields...)
-		assert.Len(t, zapFields, 1)
-		assert.Equal(t, zap.Any("field", "just a string"), zapFields[0])
+		assert.Equal(t, []zap.Field{zap.String(zaplogger.BadKey, "just a string")}, zapFields)
+	})
+
+	t.Run("with value without key", func(t *testing.T) {
+		fields := []any{42, "user", "alice"}
+		zapFields := zaplogger.ConvertToZapFields(fields...)
+		assert.Equal(t, []zap.Field{zap.Any(zaplogger.BadKey, 42), zap.String("user", "alice")}, zapFields)
 	})
 
 	t.Run("with mixed types", func(t *testing.T) {
@@ -138,10 +261,11 @@ func TestConvertToZapFields(t *testing.T) {
 			"a string",
 		}
 		zapFields := zaplogger.ConvertToZapFields(fields...)
-		assert.Len(t, zapFields, 3)
-		assert.Contains(t, zapFields, zap.String("zkey", "zvalue"))
-		assert.Contains(t, zapFields, zap.String("mkey", "mvalue"))
-		assert.Contains(t, zapFields, zap.Any("field", "a string"))
+		assert.Equal(t, []zap.Field{
+			zap.String("zkey", "zvalue"),
+			zap.String("mkey", "mvalue"),
+			zap.String(zaplogger.BadKey, "a string"),
+		}, zapFields)
 	})
 }
 
@@ -157,21 +281,17 @@ func TestConvertMapToZapFields(t *testing.T) {
 	}
 
 	fields := zaplogger.ConvertMapToZapFields(m)
-	assert.Len(t, fields, 7)
 
-	expectedFields := []zap.Field{
+	// Fields are sorted by key
+	assert.Equal(t, []zap.Field{
+		zap.Any("any", []string{"a", "b"}),
+		zap.Bool("bool", true),
 		zap.Error(assert.AnError),
-		zap.String("string", "hello"),
+		zap.Float64("float64", 78.9),
 		zap.Int("int", 123),
 		zap.Int64("int64", 456),
-		zap.Float64("float64", 78.9),
-		zap.Bool("bool", true),
-		zap.Any("any", []string{"a", "b"}),
-	}
-
-	for _, expected := range expectedFields {
-		assert.Contains(t, fields, expected)
-	}
+		zap.String("string", "hello"),
+	}, fields)
 }
 
 func TestNewLogger_Error(t *testing.T) {